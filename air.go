@@ -0,0 +1,41 @@
+package runware
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// AIR is a parsed AIR (Artificial Intelligence Resource) identifier such as
+// "runware:100@1" or "civitai:4201@130072".
+type AIR struct {
+	Provider string
+	ModelID  string
+	Version  string
+}
+
+var airPattern = regexp.MustCompile(`^([a-zA-Z0-9_-]+):([a-zA-Z0-9_.-]+)@([a-zA-Z0-9_.-]+)$`)
+
+// ParseAIR parses an identifier of the form "provider:modelID@version".
+func ParseAIR(s string) (AIR, error) {
+	match := airPattern.FindStringSubmatch(s)
+	if match == nil {
+		return AIR{}, fmt.Errorf(`invalid AIR identifier %q: expected "provider:modelID@version", e.g. "runware:100@1"`, s)
+	}
+	return AIR{Provider: match[1], ModelID: match[2], Version: match[3]}, nil
+}
+
+func (a AIR) String() string {
+	return a.Provider + ":" + a.ModelID + "@" + a.Version
+}
+
+// toModel accepts a model given as a string or an AIR.
+func toModel(value any) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case AIR:
+		return v.String(), nil
+	default:
+		return "", fmt.Errorf("expected a string or AIR, got %T", value)
+	}
+}
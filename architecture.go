@@ -0,0 +1,67 @@
+package runware
+
+import "fmt"
+
+// Architecture names the model family a task uses. It is never sent to the
+// API; it tells the client which limits and defaults apply without parsing
+// the model's AIR identifier.
+type Architecture string
+
+const (
+	ArchitectureSD15         Architecture = "sd1.5"
+	ArchitectureSDXL         Architecture = "sdxl"
+	ArchitectureFlux1Dev     Architecture = "flux1d"
+	ArchitectureFlux1Schnell Architecture = "flux1s"
+)
+
+// architectureProfile holds what the client knows about an architecture.
+type architectureProfile struct {
+	// size is the native width and height, used when a task sets neither.
+	size Definition
+	// maxSteps is the most steps worth sending.
+	maxSteps int
+}
+
+var architectureProfiles = map[Architecture]architectureProfile{
+	ArchitectureSD15:         {size: 512, maxSteps: MaxSteps},
+	ArchitectureSDXL:         {size: 1024, maxSteps: MaxSteps},
+	ArchitectureFlux1Dev:     {size: 1024, maxSteps: MaxSteps},
+	ArchitectureFlux1Schnell: {size: 1024, maxSteps: 4},
+}
+
+// ArchitectureValues returns the architectures the client knows.
+func ArchitectureValues() []Architecture {
+	return []Architecture{ArchitectureSD15, ArchitectureSDXL, ArchitectureFlux1Dev, ArchitectureFlux1Schnell}
+}
+
+// IsValid reports whether a is an architecture this package knows.
+func (a Architecture) IsValid() bool {
+	_, ok := architectureProfiles[a]
+	return ok
+}
+
+func toArchitecture(value any) (Architecture, error) {
+	var architecture Architecture
+	switch v := value.(type) {
+	case Architecture:
+		architecture = v
+	case string:
+		architecture = Architecture(v)
+	default:
+		return "", fmt.Errorf("expected an Architecture or string, got %T", value)
+	}
+	if !architecture.IsValid() {
+		return "", fmt.Errorf("unknown value %q, expected one of %v", architecture, ArchitectureValues())
+	}
+	return architecture, nil
+}
+
+// defaultDimensions returns the width and height to send for a task that sets
+// neither, taken from its architecture.
+func defaultDimensions(option RunwareOptions) (Definition, Definition) {
+	profile, ok := architectureProfiles[option.Architecture]
+	if !ok || option.Width != 0 || option.Height != 0 {
+		return option.Width, option.Height
+	}
+	return profile.size, profile.size
+}
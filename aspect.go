@@ -0,0 +1,80 @@
+package runware
+
+import (
+	"fmt"
+	"slices"
+)
+
+// MaxAspectRatio bounds how elongated a size ValidateAspect accepts: the
+// longer side may be at most this many times the shorter one, in either
+// orientation. It covers every size the models are trained on, 21:9 and 9:21
+// included, while catching a width and height swapped, such as 2048x256.
+const MaxAspectRatio = 4
+
+// presetSize is a width/height pair defined by the Definition presets, with
+// the family it belongs to.
+type presetSize struct {
+	family        string
+	width, height Definition
+}
+
+var presetSizes = []presetSize{
+	{"SD", SD_Width, SD_Height},
+	{"SD", SD_Portrait3_4Width, SD_Portrait3_4Height},
+	{"SD", SD_Portrait9_16Width, SD_Portrait9_16Height},
+	{"SD", SD_Landscape4_3Width, SD_Landscape4_3Height},
+	{"SD", SD_Landscape16_9Width, SD_Landscape16_9Height},
+	{"HD", HD_Width, HD_Height},
+	{"HD", HD_Portrait3_4Width, HD_Portrait3_4Height},
+	{"HD", HD_Portrait9_16Width, HD_Portrait9_16Height},
+	{"HD", HD_Landscape4_3Width, HD_Landscape4_3Height},
+	{"HD", HD_Landscape16_9Width, HD_Landscape16_9Height},
+}
+
+// ValidateAspect reports an error when width and height were taken from
+// different preset families, e.g. SD_Portrait3_4Width (768) combined with
+// HD_Landscape16_9Height (960), or form an aspect ratio beyond
+// MaxAspectRatio. Custom sizes within the ratio are accepted, as are preset
+// values that some preset family shares, e.g. 1024x1536 or 1024x640. Zero
+// means unset and is always accepted.
+func ValidateAspect(width, height Definition) error {
+	if width == 0 || height == 0 {
+		return nil
+	}
+	if err := checkPresetFamilies(width, height); err != nil {
+		return err
+	}
+	long, short := max(width, height), min(width, height)
+	if int(long) > MaxAspectRatio*int(short) {
+		return fmt.Errorf("width %d and height %d form an aspect ratio of %.2f:1, beyond the maximum of %d:1",
+			width, height, float64(long)/float64(short), MaxAspectRatio)
+	}
+	return nil
+}
+
+// checkPresetFamilies reports a width that is only ever a preset width of one
+// family combined with a height that is only ever a preset height of another.
+func checkPresetFamilies(width, height Definition) error {
+	var widthFamilies, heightFamilies []string
+	for _, size := range presetSizes {
+		if size.width == width && size.height == height {
+			return nil
+		}
+		if size.width == width && !slices.Contains(widthFamilies, size.family) {
+			widthFamilies = append(widthFamilies, size.family)
+		}
+		if size.height == height && !slices.Contains(heightFamilies, size.family) {
+			heightFamilies = append(heightFamilies, size.family)
+		}
+	}
+	if len(widthFamilies) == 0 || len(heightFamilies) == 0 {
+		return nil
+	}
+	for _, family := range widthFamilies {
+		if slices.Contains(heightFamilies, family) {
+			return nil
+		}
+	}
+	return fmt.Errorf("width %d is an %s preset width but height %d is an %s preset height; take both from the same preset",
+		width, widthFamilies[0], height, heightFamilies[0])
+}
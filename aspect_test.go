@@ -0,0 +1,52 @@
+package runware
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateAspect(t *testing.T) {
+	tests := []struct {
+		width, height Definition
+		wantErr       bool
+	}{
+		{SD_Width, SD_Height, false},
+		{HD_Landscape16_9Width, HD_Landscape16_9Height, false},
+		{1024, 1536, false},
+		{512, 1024, false},
+		{1024, 640, false},
+		{SD_Portrait3_4Width, HD_Landscape16_9Height, true},
+		{HD_Portrait9_16Width, SD_Portrait3_4Height, false},
+		{SD_Portrait9_16Width, HD_Portrait9_16Height, true},
+		{HD_Landscape16_9Width, SD_Landscape16_9Height, true},
+		{SD_Landscape4_3Width, HD_Portrait3_4Height, false},
+		{2048, 512, false},
+		{512, 2048, false},
+		{2048, 448, true},
+		{128, 2048, true},
+		{0, 2048, false},
+	}
+	for _, tt := range tests {
+		if err := ValidateAspect(tt.width, tt.height); (err != nil) != tt.wantErr {
+			t.Errorf("ValidateAspect(%d, %d) = %v, want error %v", tt.width, tt.height, err, tt.wantErr)
+		}
+	}
+}
+
+func TestAspectValidationInValidate(t *testing.T) {
+	client := NewGenerateImagesV1(testAPIKey, WithAspectValidation(true))
+	if err := client.Config([]map[string]any{testTask(map[string]any{"width": 1024, "height": 1536})}).Validate(); err != nil {
+		t.Errorf("Validate() rejected 1024x1536: %v", err)
+	}
+	if err := client.Config([]map[string]any{testTask(map[string]any{"width": 2048, "height": 128})}).Validate(); err == nil {
+		t.Error("Validate() accepted 2048x128 with aspect validation enabled")
+	}
+	err := client.Config([]map[string]any{testTask(map[string]any{"width": SD_Portrait3_4Width, "height": HD_Landscape16_9Height})}).Validate()
+	if err == nil || !strings.Contains(err.Error(), "task 0: width 768 is an SD preset width but height 960 is an HD preset height") {
+		t.Errorf("Validate() = %v, want the mixed presets reported", err)
+	}
+	client = NewGenerateImagesV1(testAPIKey)
+	if err := client.Config([]map[string]any{testTask(map[string]any{"width": 2048, "height": 128})}).Validate(); err != nil {
+		t.Errorf("Validate() checked the aspect ratio without WithAspectValidation: %v", err)
+	}
+}
@@ -0,0 +1,23 @@
+package runware
+
+import "context"
+
+// Result is the outcome of a GenerateV1Async call: the results and error
+// GenerateV1 would have returned.
+type Result struct {
+	Data *[]RunwareSuccessResponseBody
+	Err  error
+}
+
+// GenerateV1Async runs GenerateV1 in its own goroutine. The returned channel
+// is buffered, receives exactly one Result and is then closed, so the
+// goroutine finishes even if nobody reads from it.
+func (g *generateImagesV1Impl) GenerateV1Async() <-chan Result {
+	result := make(chan Result, 1)
+	go func() {
+		defer close(result)
+		data, err := g.GenerateV1Context(context.Background())
+		result <- Result{Data: data, Err: err}
+	}()
+	return result
+}
@@ -0,0 +1,101 @@
+package runware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"sync"
+	"time"
+)
+
+// AuditRecord is one line written by WithAuditLog, describing one task of one
+// request. It never holds the prompt itself, image data or the API key.
+type AuditRecord struct {
+	Time     time.Time `json:"time"`
+	TaskUUID string    `json:"taskUUID"`
+	TaskType TaskType  `json:"taskType"`
+	Model    string    `json:"model,omitempty"`
+	// PromptHash is the hex SHA-256 of the positive prompt, empty without one.
+	PromptHash string     `json:"promptHash,omitempty"`
+	Width      Definition `json:"width,omitempty"`
+	Height     Definition `json:"height,omitempty"`
+	// Results is the number of images returned for the task.
+	Results int     `json:"results"`
+	Cost    float64 `json:"cost"`
+	// Status is "success" or "error".
+	Status string `json:"status"`
+	// StatusCode is the HTTP status of a failed response, or zero when the
+	// request failed without one.
+	StatusCode int       `json:"statusCode,omitempty"`
+	ErrorCode  ErrorCode `json:"errorCode,omitempty"`
+	// LatencyMS is how long the whole request took, in milliseconds.
+	LatencyMS     int64  `json:"latencyMs"`
+	CorrelationID string `json:"correlationId,omitempty"`
+}
+
+// auditLog writes AuditRecords to w as JSON lines, one Write per line so that
+// concurrent requests do not interleave.
+type auditLog struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// audit writes a record for every task of a request sent to the API that
+// returned data and err after latency. Write failures are only logged.
+func (g *generateImagesV1Impl) audit(ctx context.Context, start time.Time, latency time.Duration, data *[]RunwareSuccessResponseBody, err error) {
+	if g.auditLog == nil {
+		return
+	}
+	correlationID, _ := CorrelationID(ctx)
+	for _, option := range g.options {
+		record := AuditRecord{
+			Time:          start.UTC(),
+			TaskUUID:      option.TaskUUID,
+			TaskType:      option.TaskType,
+			Model:         option.Model,
+			Status:        "success",
+			LatencyMS:     latency.Milliseconds(),
+			CorrelationID: correlationID,
+		}
+		record.Width, record.Height = g.dimensions(option)
+		if option.Prompt != "" {
+			sum := sha256.Sum256([]byte(option.Prompt))
+			record.PromptHash = hex.EncodeToString(sum[:])
+		}
+		if data != nil {
+			for _, image := range *data {
+				if image.TaskUUID == option.TaskUUID {
+					record.Results++
+					record.Cost += image.Cost
+				}
+			}
+		}
+		if err != nil {
+			record.Status = "error"
+			var apiErr *RunwareAPIError
+			if errors.As(err, &apiErr) {
+				record.StatusCode = apiErr.StatusCode
+				if len(apiErr.Errors) > 0 {
+					record.ErrorCode = apiErr.Errors[0].Code
+				}
+			}
+		}
+		g.auditLog.write(record)
+	}
+}
+
+func (a *auditLog) write(record AuditRecord) {
+	line, err := json.Marshal(record)
+	if err == nil {
+		a.mu.Lock()
+		_, err = a.w.Write(append(line, '\n'))
+		a.mu.Unlock()
+	}
+	if err != nil {
+		log.Printf("failed to write audit record for task %s: %v", record.TaskUUID, err)
+	}
+}
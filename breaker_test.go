@@ -0,0 +1,94 @@
+package runware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithCircuitBreakerRejectsInvalidSettings(t *testing.T) {
+	for _, tt := range []struct {
+		threshold int
+		cooldown  time.Duration
+	}{{0, time.Second}, {-1, time.Second}, {1, -time.Second}} {
+		client := NewGenerateImagesV1(testAPIKey, WithCircuitBreaker(tt.threshold, tt.cooldown))
+		err := client.Config([]map[string]any{testTask(nil)}).Validate()
+		if err == nil || !strings.Contains(err.Error(), "invalid circuit breaker") {
+			t.Errorf("WithCircuitBreaker(%d, %v): Validate() = %v, want it rejected", tt.threshold, tt.cooldown, err)
+		}
+	}
+}
+
+func TestCircuitBreakerTransitions(t *testing.T) {
+	const cooldown = 50 * time.Millisecond
+	serverErr := &RunwareAPIError{StatusCode: http.StatusServiceUnavailable}
+	b := &circuitBreaker{threshold: 2, cooldown: cooldown}
+	step := func(name string, want breakerState) {
+		t.Helper()
+		if b.state != want {
+			t.Fatalf("%s: state = %d, want %d", name, b.state, want)
+		}
+	}
+	send := func(err error) {
+		t.Helper()
+		if allowErr := b.allow(); allowErr != nil {
+			t.Fatalf("allow() = %v, want the request let through", allowErr)
+		}
+		b.record(err)
+	}
+
+	send(serverErr)
+	step("one failure below the threshold", breakerClosed)
+	send(&RunwareAPIError{StatusCode: http.StatusBadRequest})
+	send(serverErr)
+	step("a 4xx resets the count", breakerClosed)
+	send(serverErr)
+	step("threshold reached", breakerOpen)
+	if err := b.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("allow() while open = %v, want ErrCircuitOpen", err)
+	}
+
+	time.Sleep(cooldown)
+	if err := b.allow(); err != nil {
+		t.Fatalf("allow() after the cooldown = %v, want a probe", err)
+	}
+	step("probe in flight", breakerHalfOpen)
+	if err := b.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("allow() during the probe = %v, want ErrCircuitOpen", err)
+	}
+	b.record(context.Canceled)
+	step("canceled probe", breakerOpen)
+
+	time.Sleep(cooldown)
+	send(serverErr)
+	step("failed probe reopens", breakerOpen)
+
+	time.Sleep(cooldown)
+	send(nil)
+	step("successful probe closes", breakerClosed)
+	send(serverErr)
+	step("failures counted afresh", breakerClosed)
+}
+
+func TestCircuitBreakerFailsFast(t *testing.T) {
+	api := newFakeAPI(t, respondInTurn(
+		respondJSON(http.StatusServiceUnavailable, `{"errors":[{"message":"down"}]}`),
+		echoResults,
+	))
+	client := api.client(WithCircuitBreaker(1, time.Hour)).Config([]map[string]any{testTask(nil)})
+	if _, err := client.GenerateV1(); err == nil {
+		t.Fatal("GenerateV1() succeeded, want the 503")
+	}
+	if !client.IsOpen() {
+		t.Fatal("IsOpen() = false after reaching the threshold")
+	}
+	if _, err := client.GenerateV1(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("GenerateV1() = %v, want ErrCircuitOpen", err)
+	}
+	if api.requests() != 1 {
+		t.Errorf("sent %d requests, want 1: an open breaker must not contact the API", api.requests())
+	}
+}
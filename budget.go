@@ -0,0 +1,49 @@
+package runware
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrBudgetExceeded matches, through errors.Is, the *BudgetExceededError
+// returned once the budget set by WithCostBudget has been spent.
+var ErrBudgetExceeded = errors.New("cost budget exceeded")
+
+// BudgetExceededError reports that a call was refused because the client has
+// already spent its cost budget.
+type BudgetExceededError struct {
+	Budget float64
+	Spent  float64
+}
+
+func (e *BudgetExceededError) Error() string {
+	return fmt.Sprintf("cost budget exceeded: spent %g of %g", e.Spent, e.Budget)
+}
+
+func (e *BudgetExceededError) Is(target error) bool { return target == ErrBudgetExceeded }
+
+// checkBudget refuses a call once the reported cost of earlier calls has
+// reached the budget.
+func (g *generateImagesV1Impl) checkBudget() error {
+	if g.costBudget <= 0 {
+		return nil
+	}
+	g.state.mu.Lock()
+	defer g.state.mu.Unlock()
+	if g.state.spent >= g.costBudget {
+		return &BudgetExceededError{Budget: g.costBudget, Spent: g.state.spent}
+	}
+	return nil
+}
+
+// spend adds the reported cost of results to the client's total.
+func (g *generateImagesV1Impl) spend(results *[]RunwareSuccessResponseBody) {
+	if g.costBudget <= 0 || results == nil {
+		return
+	}
+	g.state.mu.Lock()
+	defer g.state.mu.Unlock()
+	for _, image := range *results {
+		g.state.spent += image.Cost
+	}
+}
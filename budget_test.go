@@ -0,0 +1,34 @@
+package runware
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestCostBudget(t *testing.T) {
+	api := newFakeAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		var tasks []map[string]any
+		json.NewDecoder(r.Body).Decode(&tasks)
+		if tasks[0]["includeCost"] != true {
+			t.Errorf("includeCost = %v, want true", tasks[0]["includeCost"])
+		}
+		respondJSON(http.StatusOK, `{"data":[{"taskType":"imageInference","taskUUID":"`+tasks[0]["taskUUID"].(string)+`","imageUUID":"i","cost":0.006}]}`)(w, r)
+	})
+	client := api.client(WithCostBudget(0.005)).Config([]map[string]any{testTask(map[string]any{"includeCost": true})})
+	if _, err := client.GenerateV1(); err != nil {
+		t.Fatalf("first GenerateV1() = %v, want it within budget", err)
+	}
+	_, err := client.GenerateV1()
+	if !errors.Is(err, ErrBudgetExceeded) {
+		t.Fatalf("second GenerateV1() = %v, want ErrBudgetExceeded", err)
+	}
+	var budgetErr *BudgetExceededError
+	if !errors.As(err, &budgetErr) || budgetErr.Spent != 0.006 || budgetErr.Budget != 0.005 {
+		t.Errorf("BudgetExceededError = %+v, want 0.006 spent of 0.005", budgetErr)
+	}
+	if api.requests() != 1 {
+		t.Errorf("sent %d requests, want the second call refused before sending", api.requests())
+	}
+}
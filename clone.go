@@ -0,0 +1,49 @@
+package runware
+
+import (
+	"maps"
+	"slices"
+)
+
+// Clone returns a copy of the client with its own copy of the configured
+// tasks, so either can be reconfigured without affecting the other. The copy
+// keeps the API key and every option, and shares the underlying HTTP client,
+// circuit breaker and metrics; it starts open, with no response history and,
+// with WithCostBudget, nothing spent.
+func (g *generateImagesV1Impl) Clone() GenerateImagesV1 {
+	clone := *g
+	clone.options = cloneOptions(g.options)
+	clone.endpoints = slices.Clone(g.endpoints)
+	clone.state = &clientState{}
+	return &clone
+}
+
+// cloneOptions copies options, including the values their pointer and slice
+// fields refer to.
+func cloneOptions(options []RunwareOptions) []RunwareOptions {
+	if options == nil {
+		return nil
+	}
+	cloned := make([]RunwareOptions, len(options))
+	for i, option := range options {
+		option.CheckNSFW = clonePointer(option.CheckNSFW)
+		option.IncludeCost = clonePointer(option.IncludeCost)
+		option.MaskMargin = clonePointer(option.MaskMargin)
+		option.Strength = clonePointer(option.Strength)
+		option.CFGScale = clonePointer(option.CFGScale)
+		option.ClipSkip = clonePointer(option.ClipSkip)
+		option.Seed = clonePointer(option.Seed)
+		option.InputImages = slices.Clone(option.InputImages)
+		option.Raw = maps.Clone(option.Raw)
+		cloned[i] = option
+	}
+	return cloned
+}
+
+func clonePointer[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
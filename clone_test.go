@@ -0,0 +1,29 @@
+package runware
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCloneDoesNotShareTasks(t *testing.T) {
+	original := NewGenerateImagesV1(testAPIKey, WithRetries(2, 0)).Config([]map[string]any{
+		testTask(map[string]any{"inputImages": []string{"QQ==", "Qg=="}, "raw": map[string]any{"acceleration": "high"}}),
+	}).(*generateImagesV1Impl)
+	before := cloneOptions(original.options)
+
+	clone := original.Clone().(*generateImagesV1Impl)
+	clone.Override(map[string]any{"prompt": "a different prompt", "width": 1024})
+	clone.options[0].InputImages[0] = "Qw=="
+	clone.options[0].Raw["acceleration"] = "none"
+	clone.options = append(clone.options, RunwareOptions{TaskType: ImageInference})
+
+	if !reflect.DeepEqual(original.options, before) {
+		t.Errorf("changing the clone changed the original:\n got %+v\nwant %+v", original.options, before)
+	}
+	if clone.apiKey != original.apiKey || clone.maxRetries != original.maxRetries {
+		t.Error("clone did not keep the client settings")
+	}
+	if clone.state == original.state {
+		t.Error("clone shares the response state of the original")
+	}
+}
@@ -0,0 +1,63 @@
+package runware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrClosed is returned by calls made on a client after Close.
+var ErrClosed = errors.New("client is closed")
+
+// Close shuts the client down: calls in flight are cancelled and fail with an
+// error matching ErrClosed, as do all later calls, and idle connections of
+// the HTTP client are closed. Closing a closed client does nothing. Copies
+// made by Clone share the HTTP client but are closed separately.
+func (g *generateImagesV1Impl) Close() error {
+	g.state.mu.Lock()
+	if g.state.closed {
+		g.state.mu.Unlock()
+		return nil
+	}
+	g.state.closed = true
+	close(g.state.closing())
+	g.state.mu.Unlock()
+	g.httpClient.CloseIdleConnections()
+	return nil
+}
+
+// closing returns the channel closed by Close. s.mu must be held.
+func (s *clientState) closing() chan struct{} {
+	if s.done == nil {
+		s.done = make(chan struct{})
+	}
+	return s.done
+}
+
+// bindClose returns a context that is cancelled when the client is closed,
+// along with the function releasing it, or ErrClosed if it already is.
+func (g *generateImagesV1Impl) bindClose(ctx context.Context) (context.Context, context.CancelFunc, error) {
+	g.state.mu.Lock()
+	closed, done := g.state.closed, g.state.closing()
+	g.state.mu.Unlock()
+	if closed {
+		return nil, nil, ErrClosed
+	}
+	ctx, cancel := context.WithCancelCause(ctx)
+	go func() {
+		select {
+		case <-done:
+			cancel(ErrClosed)
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, func() { cancel(nil) }, nil
+}
+
+// closedErr marks err as caused by Close when ctx was cancelled by it.
+func closedErr(ctx context.Context, err error) error {
+	if err != nil && !errors.Is(err, ErrClosed) && errors.Is(context.Cause(ctx), ErrClosed) {
+		return fmt.Errorf("%w: %w", ErrClosed, err)
+	}
+	return err
+}
@@ -0,0 +1,247 @@
+package runware
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"reflect"
+	"strconv"
+)
+
+// Numeric values passed to Config are coerced to the type the field needs, so
+// maps produced by json.Unmarshal (float64) or plain Go literals (int) work as
+// well as the typed constants:
+//
+//   - any signed or unsigned integer type, and Definition, is accepted;
+//   - float32 and float64 are accepted when they hold a whole number;
+//   - json.Number is accepted when it holds a whole number, such as 768 or
+//     768.0;
+//   - values outside the range of the target field are rejected.
+//
+// Anything else is reported as an error from GenerateV1 instead of panicking.
+
+func toInt64(value any) (int64, error) {
+	switch v := value.(type) {
+	case int:
+		return int64(v), nil
+	case int8:
+		return int64(v), nil
+	case int16:
+		return int64(v), nil
+	case int32:
+		return int64(v), nil
+	case int64:
+		return v, nil
+	case uint:
+		return uintToInt64(uint64(v))
+	case uint8:
+		return int64(v), nil
+	case uint16:
+		return int64(v), nil
+	case uint32:
+		return int64(v), nil
+	case uint64:
+		return uintToInt64(v)
+	case Definition:
+		return int64(v), nil
+	case float32:
+		return floatToInt64(float64(v))
+	case float64:
+		return floatToInt64(v)
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n, nil
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return 0, err
+		}
+		return floatToInt64(f)
+	default:
+		return 0, fmt.Errorf("expected a number, got %T", value)
+	}
+}
+
+func uintToInt64(v uint64) (int64, error) {
+	if v > math.MaxInt64 {
+		return 0, fmt.Errorf("value %d is out of range", v)
+	}
+	return int64(v), nil
+}
+
+func floatToInt64(v float64) (int64, error) {
+	if v != math.Trunc(v) || math.IsInf(v, 0) || math.IsNaN(v) {
+		return 0, fmt.Errorf("expected a whole number, got %v", v)
+	}
+	if v < math.MinInt64 || v >= math.MaxInt64 {
+		return 0, fmt.Errorf("value %v is out of range", v)
+	}
+	return int64(v), nil
+}
+
+// toSeed accepts any integer toInt64 does, or a decimal string, which keeps
+// seeds above 2^53 exact where a float64 would not.
+func toSeed(value any) (int64, error) {
+	if s, ok := value.(string); ok {
+		return strconv.ParseInt(s, 10, 64)
+	}
+	return toInt64(value)
+}
+
+// toIntInRange coerces value to an integer and checks it lies in [lo, hi].
+func toIntInRange(value any, lo, hi int64) (int64, error) {
+	n, err := toInt64(value)
+	if err != nil {
+		return 0, err
+	}
+	if n < lo || n > hi {
+		return 0, fmt.Errorf("value %d is out of range [%d, %d]", n, lo, hi)
+	}
+	return n, nil
+}
+
+// toFloat64 accepts any integer or floating-point type.
+func toFloat64(value any) (float64, error) {
+	switch v := value.(type) {
+	case float32:
+		return float64(v), nil
+	case float64:
+		return v, nil
+	case Strength:
+		return v.Float(), nil
+	case json.Number:
+		return v.Float64()
+	default:
+		n, err := toInt64(value)
+		if err != nil {
+			return 0, fmt.Errorf("expected a number, got %T", value)
+		}
+		return float64(n), nil
+	}
+}
+
+// toTaskType accepts a TaskType or its string form.
+func toTaskType(value any) (TaskType, error) {
+	switch v := value.(type) {
+	case TaskType:
+		return v, nil
+	case string:
+		return normalizeTaskType(v), nil
+	default:
+		return "", fmt.Errorf("expected a TaskType or string, got %T", value)
+	}
+}
+
+// toOutputType accepts an OutputType or its string form.
+func toOutputType(value any) (OutputType, error) {
+	switch v := value.(type) {
+	case OutputType:
+		return v, nil
+	case string:
+		return normalizeOutputType(v), nil
+	default:
+		return "", fmt.Errorf("expected an OutputType or string, got %T", value)
+	}
+}
+
+// toOutputFormat accepts an OutputFormat or its string form.
+func toOutputFormat(value any) (OutputFormat, error) {
+	switch v := value.(type) {
+	case OutputFormat:
+		return v, nil
+	case string:
+		return normalizeOutputFormat(v), nil
+	default:
+		return "", fmt.Errorf("expected an OutputFormat or string, got %T", value)
+	}
+}
+
+// toPromptWeighting accepts a PromptWeighting or its string form.
+func toPromptWeighting(value any) (PromptWeighting, error) {
+	switch v := value.(type) {
+	case PromptWeighting:
+		return v, nil
+	case string:
+		return PromptWeighting(v), nil
+	default:
+		return "", fmt.Errorf("expected a PromptWeighting or string, got %T", value)
+	}
+}
+
+// withoutNils returns data without the keys whose value is nil, including
+// typed nil pointers, maps and slices, so that a map built from optional
+// inputs treats a missing input as unset.
+func withoutNils(data map[string]any) map[string]any {
+	set := make(map[string]any, len(data))
+	for key, value := range data {
+		if !isNil(value) {
+			set[key] = value
+		}
+	}
+	return set
+}
+
+func isNil(value any) bool {
+	if value == nil {
+		return true
+	}
+	switch v := reflect.ValueOf(value); v.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Interface, reflect.Func, reflect.Chan:
+		return v.IsNil()
+	}
+	return false
+}
+
+// toRawFields accepts a map of fields that can be encoded as JSON.
+func toRawFields(value any) (map[string]any, error) {
+	fields, ok := value.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("expected a map[string]any, got %T", value)
+	}
+	for key, field := range fields {
+		if _, err := json.Marshal(field); err != nil {
+			return nil, fmt.Errorf("field %q: %w", key, err)
+		}
+	}
+	return maps.Clone(fields), nil
+}
+
+// toStringSlice accepts a []string, or a []any of strings as produced by
+// json.Unmarshal.
+func toStringSlice(value any) ([]string, error) {
+	switch v := value.(type) {
+	case []string:
+		return v, nil
+	case []any:
+		values := make([]string, len(v))
+		for i, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("expected strings, got %T at index %d", item, i)
+			}
+			values[i] = s
+		}
+		return values, nil
+	default:
+		return nil, fmt.Errorf("expected a []string, got %T", value)
+	}
+}
+
+// toString accepts a string.
+func toString(value any) (string, error) {
+	s, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("expected a string, got %T", value)
+	}
+	return s, nil
+}
+
+// toBool accepts a bool.
+func toBool(value any) (bool, error) {
+	b, ok := value.(bool)
+	if !ok {
+		return false, fmt.Errorf("expected a bool, got %T", value)
+	}
+	return b, nil
+}
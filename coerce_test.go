@@ -0,0 +1,101 @@
+package runware
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// configJSON configures a client from tasks decoded by encoding/json, as a
+// caller loading them from a file would.
+func configJSON(t *testing.T, tasks string, useNumber bool) *generateImagesV1Impl {
+	t.Helper()
+	var data []map[string]any
+	decoder := json.NewDecoder(strings.NewReader(tasks))
+	if useNumber {
+		decoder.UseNumber()
+	}
+	if err := decoder.Decode(&data); err != nil {
+		t.Fatal(err)
+	}
+	return NewGenerateImagesV1(testAPIKey).Config(data).(*generateImagesV1Impl)
+}
+
+func TestConfigCoercesJSONNumbers(t *testing.T) {
+	const tasks = `[{
+		"taskType": "imageInference", "prompt": "a red fox", "model": "runware:100@1",
+		"width": 512, "height": 768.0, "steps": 30, "results": 2, "outputQuality": 90,
+		"CFGScale": 7, "clipSkip": 2, "seed": 1234567890123, "strength": 0.5, "maskMargin": 16
+	}]`
+	for _, useNumber := range []bool{false, true} {
+		g := configJSON(t, tasks, useNumber)
+		if g.configErr != nil {
+			t.Fatalf("UseNumber %v: %v", useNumber, g.configErr)
+		}
+		got := g.options[0]
+		if got.Width != 512 || got.Height != 768 || got.Steps != 30 || got.NumberOfResults != 2 || got.OutputQuality != 90 {
+			t.Errorf("UseNumber %v: integers = %+v", useNumber, got)
+		}
+		if *got.CFGScale != 7 || *got.ClipSkip != 2 || *got.Seed != 1234567890123 || *got.Strength != 0.5 || *got.MaskMargin != 16 {
+			t.Errorf("UseNumber %v: pointers = %v %v %v %v %v", useNumber, *got.CFGScale, *got.ClipSkip, *got.Seed, *got.Strength, *got.MaskMargin)
+		}
+	}
+}
+
+func TestConfigRejectsBadNumbers(t *testing.T) {
+	tests := []struct {
+		key   string
+		value string
+	}{
+		{"width", "512.5"},
+		{"height", "-64"},
+		{"steps", `"thirty"`},
+		{"results", "300"},
+		{"outputQuality", "1e300"},
+		{"clipSkip", "true"},
+		{"CFGScale", `"high"`},
+		{"seed", `"12x"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			var buf bytes.Buffer
+			buf.WriteString(`[{"taskType":"imageInference","prompt":"a red fox","model":"runware:100@1",`)
+			buf.WriteString(`"` + tt.key + `":` + tt.value + `}]`)
+			g := configJSON(t, buf.String(), false)
+			err := g.Validate()
+			if err == nil || !strings.Contains(err.Error(), "invalid "+tt.key) {
+				t.Errorf("Validate() = %v, want an error naming %s", err, tt.key)
+			}
+		})
+	}
+}
+
+func TestConfigRejectsWrongTypes(t *testing.T) {
+	tests := []struct {
+		key   string
+		value any
+	}{
+		{"taskUUID", 42},
+		{"prompt", 42},
+		{"negativePrompt", []string{"blurry"}},
+		{"uploadEndpoint", true},
+		{"checkNSFW", "yes"},
+		{"includeCost", 1},
+		{"seedImage", []byte("image")},
+		{"inputImage", 3.5},
+		{"maskImage", map[string]any{}},
+		{"style", PNG},
+		{"lossless", "true"},
+		{"vaeTiling", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			task := testTask(map[string]any{tt.key: tt.value})
+			err := NewGenerateImagesV1(testAPIKey).Config([]map[string]any{task}).Validate()
+			if err == nil || !strings.Contains(err.Error(), "task 0: invalid "+tt.key+": expected a") {
+				t.Errorf("Validate() = %v, want an error naming %s", err, tt.key)
+			}
+		})
+	}
+}
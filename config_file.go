@@ -0,0 +1,61 @@
+package runware
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFromFile loads a batch of tasks from a JSON (.json) or YAML (.yaml,
+// .yml) file holding a list of RunwareOptions, keyed by their JSON field
+// names. Output types and formats may be written in any case ("png", "url").
+// The loaded tasks are validated before the client is returned.
+func (g *generateImagesV1Impl) ConfigFromFile(path string) (GenerateImagesV1, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+	case ".yaml", ".yml":
+		// Go through JSON so both formats share the RunwareOptions field names.
+		var doc any
+		if err := yaml.Unmarshal(raw, &doc); err != nil {
+			return nil, fmt.Errorf("failed to parse config file: %w", err)
+		}
+		if raw, err = json.Marshal(doc); err != nil {
+			return nil, fmt.Errorf("failed to parse config file: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported config file extension %q, expected .json, .yaml or .yml", ext)
+	}
+	var options []RunwareOptions
+	if err := json.Unmarshal(raw, &options); err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+	for i := range options {
+		options[i].OutputType = normalizeOutputType(string(options[i].OutputType))
+		options[i].OutputFormat = normalizeOutputFormat(string(options[i].OutputFormat))
+	}
+	g.setOptions(options)
+	if err := g.Validate(); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// setOptions configures the client from already typed tasks.
+func (g *generateImagesV1Impl) setOptions(options []RunwareOptions) {
+	g.reset(options)
+	for i := range g.options {
+		if g.options[i].TaskUUID == "" {
+			g.options[i].TaskUUID = uuid.New().String()
+			g.explicitUUIDs = false
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package runware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	"image/png"
+	"net/http"
+	"strings"
+)
+
+// maxDownloadBytes bounds how much of an image AsPNG downloads.
+const maxDownloadBytes = 100 << 20
+
+// AsPNG returns the image as PNG bytes, whatever format it was generated in
+// (WEBP needs the decoder that -tags runware_nowebp leaves out).
+// Base64 and data URI results are decoded in place. URL results are first
+// downloaded with client, within ctx and up to 100MB; pass the HTTPClient of
+// the GenerateImagesV1 that produced the result to use its transport, or nil
+// for http.DefaultClient. PNG images are returned without re-encoding.
+func (r RunwareSuccessResponseBody) AsPNG(ctx context.Context, client *http.Client) ([]byte, error) {
+	data, err := r.imageData(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	if http.DetectContentType(data) == "image/png" {
+		return data, nil
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image %s: %w", r.ImageUUID, decodeErr(data, err))
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode image %s as PNG: %w", r.ImageUUID, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// ImageBytes returns the raw bytes of the image carried by a base64Data or
+// dataURI result. Standard and URL-safe base64 are accepted, with or without
+// padding. A data URI that declares a media type other than an image is an
+// error, as is a result that only carries a URL; use AsPNG to download those.
+func (r RunwareSuccessResponseBody) ImageBytes() ([]byte, error) {
+	if r.ImageBase64Data == "" && r.ImageDataURI == "" {
+		if r.ImageUrl != "" {
+			return nil, fmt.Errorf("image %s is only available by URL, download it from %s or request %s or %s output", r.ImageUUID, r.ImageUrl, Base64Data, DataURI)
+		}
+		return nil, fmt.Errorf("image %s carries no image data", r.ImageUUID)
+	}
+	data, mediaType, err := decodeImage(r)
+	if err != nil {
+		return nil, fmt.Errorf("image %s: %w", r.ImageUUID, err)
+	}
+	if mediaType != "" && !strings.HasPrefix(strings.ToLower(mediaType), "image/") {
+		return nil, fmt.Errorf("image %s: data URI has media type %q, expected an image", r.ImageUUID, mediaType)
+	}
+	return data, nil
+}
+
+// DecodeImage decodes the image carried by a base64Data or dataURI result,
+// returning the format name as image.Decode does: "png", "jpeg" or "webp".
+func (r RunwareSuccessResponseBody) DecodeImage() (image.Image, string, error) {
+	data, err := r.ImageBytes()
+	if err != nil {
+		return nil, "", err
+	}
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to decode image %s: %w", r.ImageUUID, decodeErr(data, err))
+	}
+	return img, format, nil
+}
+
+// decodeErr explains an image.ErrFormat from decoding a WEBP image in a
+// build without the WEBP decoder.
+func decodeErr(data []byte, err error) error {
+	if missingWEBP(data, err) {
+		return fmt.Errorf("%w: WEBP support was left out of this build by the runware_nowebp tag", err)
+	}
+	return err
+}
+
+// missingWEBP reports whether err is image.Decode failing on a WEBP image
+// because the decoder was not built in.
+func missingWEBP(data []byte, err error) bool {
+	return !webpSupported && errors.Is(err, image.ErrFormat) && http.DetectContentType(data) == "image/webp"
+}
+
+// imageData returns the raw bytes of the image, downloading it with client
+// when the result only carries a URL.
+func (r RunwareSuccessResponseBody) imageData(ctx context.Context, client *http.Client) ([]byte, error) {
+	if r.ImageBase64Data != "" || r.ImageDataURI != "" || r.ImageUrl == "" {
+		data, _, err := decodeImage(r)
+		return data, err
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	data, _, err := downloadImage(ctx, client, r, maxDownloadBytes)
+	return data, err
+}
@@ -0,0 +1,124 @@
+package runware
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"image"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestAsPNGDownloadsWithClient(t *testing.T) {
+	jpegData := testJPEG(t)
+	api := newFakeAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/image.jpg" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "image/jpeg")
+		w.Write(jpegData)
+	})
+	var used atomic.Int64
+	client := api.client(WithTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		used.Add(1)
+		return http.DefaultTransport.RoundTrip(req)
+	})))
+
+	png, err := RunwareSuccessResponseBody{ImageUUID: "i", ImageUrl: api.URL + "/image.jpg"}.AsPNG(context.Background(), client.HTTPClient())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(png, []byte("\x89PNG")) {
+		t.Error("AsPNG did not return PNG data")
+	}
+	if used.Load() != 1 {
+		t.Errorf("download went through the client's transport %d times, want 1", used.Load())
+	}
+
+	tests := []struct {
+		name string
+		ctx  func() context.Context
+		url  string
+		is   error
+	}{
+		{"missing", context.Background, api.URL + "/missing.jpg", nil},
+		{"canceled", func() context.Context {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			return ctx
+		}, api.URL + "/image.jpg", context.Canceled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := RunwareSuccessResponseBody{ImageUUID: "i", ImageUrl: tt.url}.AsPNG(tt.ctx(), client.HTTPClient())
+			if err == nil || tt.is != nil && !errors.Is(err, tt.is) {
+				t.Errorf("AsPNG = %v, want an error matching %v", err, tt.is)
+			}
+		})
+	}
+}
+
+func TestDownloadImageLimit(t *testing.T) {
+	api := newFakeAPI(t, respondText(http.StatusOK, "image/png", string(testPNG(t))))
+	image := RunwareSuccessResponseBody{ImageUUID: "i", ImageUrl: api.URL}
+	if _, _, err := downloadImage(context.Background(), http.DefaultClient, image, 16); !errors.Is(err, ErrResponseTooLarge) {
+		t.Errorf("downloadImage = %v, want ErrResponseTooLarge", err)
+	}
+	data, mediaType, err := downloadImage(context.Background(), http.DefaultClient, image, 1<<20)
+	if err != nil || mediaType != "image/png" || !bytes.Equal(data, testPNG(t)) {
+		t.Errorf("downloadImage = %d bytes, %q, %v", len(data), mediaType, err)
+	}
+}
+
+// fixture reads an image from testdata/images.
+func fixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "images", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestDecodeImage(t *testing.T) {
+	tests := []struct {
+		name       string
+		result     RunwareSuccessResponseBody
+		wantFormat string
+		wantSize   image.Point
+	}{
+		{"png base64", RunwareSuccessResponseBody{ImageUUID: "p", ImageBase64Data: base64.StdEncoding.EncodeToString(fixture(t, "fixture.png"))}, "png", image.Pt(4, 3)},
+		{"jpeg data URI", RunwareSuccessResponseBody{ImageUUID: "j", ImageDataURI: "data:image/jpeg;base64," + base64.StdEncoding.EncodeToString(fixture(t, "fixture.jpg"))}, "jpeg", image.Pt(4, 3)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img, format, err := tt.result.DecodeImage()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if format != tt.wantFormat || img.Bounds().Size() != tt.wantSize {
+				t.Errorf("DecodeImage = %q %v, want %q %v", format, img.Bounds().Size(), tt.wantFormat, tt.wantSize)
+			}
+		})
+	}
+}
+
+func TestDecodeImageCorrupt(t *testing.T) {
+	// A PNG cut short still has a PNG signature, so it fails in the decoder.
+	truncated := fixture(t, "fixture.png")[:40]
+	result := RunwareSuccessResponseBody{ImageUUID: "3f2a9c1e-corrupt", ImageBase64Data: base64.StdEncoding.EncodeToString(truncated)}
+	_, _, err := result.DecodeImage()
+	if err == nil || !strings.Contains(err.Error(), "failed to decode image 3f2a9c1e-corrupt") {
+		t.Fatalf("DecodeImage = %v, want a decode error naming the imageUUID", err)
+	}
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("DecodeImage = %v, want the decoder's error wrapped", err)
+	}
+}
@@ -0,0 +1,35 @@
+package runware
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// CorrelationIDHeader is the request header carrying the correlation ID.
+const CorrelationIDHeader = "X-Correlation-Id"
+
+type correlationIDKey struct{}
+
+// WithCorrelationID returns a copy of ctx carrying id. Calls made with the
+// returned context send id in the X-Correlation-Id header and report it in
+// logs, *RunwareAPIError and *TransportError, so a request can be traced
+// across services. Calls whose context has no ID get a random one.
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationIDKey{}, id)
+}
+
+// CorrelationID returns the correlation ID carried by ctx, if any.
+func CorrelationID(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(correlationIDKey{}).(string)
+	return id, ok && id != ""
+}
+
+// ensureCorrelationID returns ctx, adding a random correlation ID when it
+// does not carry one yet.
+func ensureCorrelationID(ctx context.Context) context.Context {
+	if _, ok := CorrelationID(ctx); ok {
+		return ctx
+	}
+	return WithCorrelationID(ctx, uuid.New().String())
+}
@@ -0,0 +1,66 @@
+package runware
+
+import "fmt"
+
+// Dimension constraints enforced by the API for width and height.
+const (
+	MinDimension      Definition = 128
+	MaxDimension      Definition = 2048
+	DimensionMultiple Definition = 64
+)
+
+// checkDimension reports whether a width or height is acceptable to the API.
+// Zero means unset and is always accepted.
+func checkDimension(name string, value Definition) error {
+	if value == 0 {
+		return nil
+	}
+	if value < MinDimension || value > MaxDimension {
+		return fmt.Errorf("%s %d is outside the allowed range [%d, %d]", name, value, MinDimension, MaxDimension)
+	}
+	if value%DimensionMultiple != 0 {
+		return fmt.Errorf("%s %d is not a multiple of %d", name, value, DimensionMultiple)
+	}
+	return nil
+}
+
+// roundDimension returns the valid dimension nearest to value. Zero means
+// unset and is returned unchanged.
+func roundDimension(value Definition) Definition {
+	if value == 0 {
+		return 0
+	}
+	// Clamp first: rounding values near the top of the range would overflow.
+	value = min(max(value, MinDimension), MaxDimension)
+	return (value + DimensionMultiple/2) / DimensionMultiple * DimensionMultiple
+}
+
+// dimensions returns the width and height to send for a task, defaulting to
+// its architecture's native size and rounded when WithDimensionRounding is
+// enabled.
+func (g *generateImagesV1Impl) dimensions(option RunwareOptions) (Definition, Definition) {
+	width, height := defaultDimensions(option)
+	if g.roundDimensions {
+		return roundDimension(width), roundDimension(height)
+	}
+	return width, height
+}
+
+// checkDimensions validates the width and height a task would send, and their
+// aspect ratio when WithAspectValidation is enabled.
+func (g *generateImagesV1Impl) checkDimensions(i int, option RunwareOptions) []error {
+	width, height := g.dimensions(option)
+	var errs []error
+	if err := checkDimension("width", width); err != nil {
+		errs = append(errs, fmt.Errorf("task %d: %w", i, err))
+	}
+	if err := checkDimension("height", height); err != nil {
+		errs = append(errs, fmt.Errorf("task %d: %w", i, err))
+	}
+	if g.validateAspect {
+		if err := ValidateAspect(width, height); err != nil {
+			errs = append(errs, fmt.Errorf("task %d: %w", i, err))
+		}
+	}
+	return errs
+}
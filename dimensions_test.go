@@ -0,0 +1,33 @@
+package runware
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRoundDimension(t *testing.T) {
+	tests := []struct {
+		value, want Definition
+	}{
+		{0, 0},
+		{1, MinDimension},
+		{127, MinDimension},
+		{128, 128},
+		{159, 128},
+		{160, 192},
+		{1000, 1024},
+		{2047, 2048},
+		{2048, 2048},
+		{2100, MaxDimension},
+		{math.MaxUint16 - DimensionMultiple/2, MaxDimension},
+		{math.MaxUint16, MaxDimension},
+	}
+	for _, tt := range tests {
+		if got := roundDimension(tt.value); got != tt.want {
+			t.Errorf("roundDimension(%d) = %d, want %d", tt.value, got, tt.want)
+		}
+		if got := roundDimension(tt.value); got != 0 && checkDimension("width", got) != nil {
+			t.Errorf("roundDimension(%d) = %d, which is not a valid dimension", tt.value, got)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package runware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DefaultEndpoint is the base URL requests are sent to unless WithEndpoints
+// says otherwise.
+const DefaultEndpoint = "https://api.runware.ai"
+
+// DefaultEndpointCooldown is how long an endpoint that failed is skipped for
+// unless WithEndpointCooldown says otherwise.
+const DefaultEndpointCooldown = 30 * time.Second
+
+// DefaultAPIVersion is the API version path used unless WithAPIVersion says
+// otherwise.
+const DefaultAPIVersion = "v1"
+
+// apiVersionPattern accepts a single path segment such as v1, v2 or v2beta.
+var apiVersionPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// checkEndpoint validates a base URL given to WithEndpoints.
+func checkEndpoint(endpoint string) error {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("%q is not an absolute http(s) URL", endpoint)
+	}
+	return nil
+}
+
+// addOptionErr records a problem with an Option. Options cannot return
+// errors, so they are reported by Validate and GenerateV1.
+func (g *generateImagesV1Impl) addOptionErr(err error) {
+	g.optionErr = errors.Join(g.optionErr, err)
+}
+
+// endpointURLs returns the request URL, made of the base URL and the API
+// version, for every configured endpoint in the order they should be tried.
+func (g *generateImagesV1Impl) endpointURLs() []string {
+	endpoints := g.endpoints
+	if len(endpoints) == 0 {
+		endpoints = []string{DefaultEndpoint}
+	}
+	urls := make([]string, len(endpoints))
+	for i, endpoint := range endpoints {
+		urls[i] = strings.TrimRight(endpoint, "/") + "/" + g.apiVersion
+	}
+	return urls
+}
+
+// sendFailover sends one attempt to the first healthy endpoint, moving on to
+// the next one straight away, without backoff, when an endpoint cannot be
+// reached or answers with a server error. Each endpoint is tried at most once
+// per attempt. Any other error, including a 4xx response, is returned as is.
+func sendFailover(ctx context.Context, g *generateImagesV1Impl, urls []string) (*[]RunwareSuccessResponseBody, error) {
+	var (
+		data *[]RunwareSuccessResponseBody
+		err  error
+	)
+	for _, u := range g.healthyURLs(urls) {
+		data, err = sendRequest(ctx, g, u)
+		if !shouldFailover(ctx, err) {
+			if err == nil {
+				g.markEndpoint(u, true)
+			}
+			return data, err
+		}
+		g.markEndpoint(u, false)
+	}
+	return data, err
+}
+
+// shouldFailover reports whether err means the endpoint could not be reached
+// or is failing, so the next one is worth trying while ctx is still live.
+func shouldFailover(ctx context.Context, err error) bool {
+	return err != nil && ctx.Err() == nil && isServerFailure(err)
+}
+
+// healthyURLs returns urls without the endpoints that failed within the
+// cooldown. When every endpoint is cooling down they are all returned, in
+// order, since refusing to send would be worse than trying a bad one.
+func (g *generateImagesV1Impl) healthyURLs(urls []string) []string {
+	if len(urls) < 2 {
+		return urls
+	}
+	now := time.Now()
+	g.state.mu.Lock()
+	defer g.state.mu.Unlock()
+	healthy := make([]string, 0, len(urls))
+	for _, u := range urls {
+		if now.After(g.state.endpointDownUntil[u]) {
+			healthy = append(healthy, u)
+		}
+	}
+	if len(healthy) == 0 {
+		return urls
+	}
+	return healthy
+}
+
+// markEndpoint records whether a request to the endpoint at u succeeded. A
+// failed endpoint is skipped until the cooldown has passed.
+func (g *generateImagesV1Impl) markEndpoint(u string, ok bool) {
+	if len(g.endpoints) < 2 {
+		return
+	}
+	g.state.mu.Lock()
+	defer g.state.mu.Unlock()
+	if ok {
+		delete(g.state.endpointDownUntil, u)
+		return
+	}
+	if g.state.endpointDownUntil == nil {
+		g.state.endpointDownUntil = make(map[string]time.Time)
+	}
+	g.state.endpointDownUntil[u] = time.Now().Add(g.endpointCooldown)
+}
+
+// postFailover sends body, a request other than the configured tasks, to the
+// healthy endpoints in turn like sendFailover.
+func postFailover(ctx context.Context, g *generateImagesV1Impl, body []byte) (*[]RunwareSuccessResponseBody, error) {
+	var (
+		data *[]RunwareSuccessResponseBody
+		err  error
+	)
+	for _, u := range g.healthyURLs(g.endpointURLs()) {
+		data, err = post(ctx, g, u, body)
+		if !shouldFailover(ctx, err) {
+			if err == nil {
+				g.markEndpoint(u, true)
+			}
+			return data, err
+		}
+		g.markEndpoint(u, false)
+	}
+	return data, err
+}
+
+func post(ctx context.Context, g *generateImagesV1Impl, u string, body []byte) (*[]RunwareSuccessResponseBody, error) {
+	req, err := newAPIRequest(ctx, g, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	return doRequest(g, g.httpClient, req)
+}
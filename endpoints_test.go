@@ -0,0 +1,90 @@
+package runware
+
+import (
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// refusedEndpoint returns the address of a listener that has been closed, so
+// connecting to it is refused.
+func refusedEndpoint(t *testing.T) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+	return "http://" + addr
+}
+
+func TestFailoverFromRefusedEndpoint(t *testing.T) {
+	api := newFakeAPI(t, echoResults)
+	client := NewGenerateImagesV1(testAPIKey, WithEndpoints([]string{refusedEndpoint(t), api.URL}))
+	data, err := client.Config([]map[string]any{testTask(nil)}).GenerateV1()
+	if err != nil {
+		t.Fatalf("GenerateV1() = %v, want the second endpoint to answer", err)
+	}
+	if len(*data) != 1 || api.requests() != 1 {
+		t.Errorf("got %d results from %d requests to the second endpoint, want 1 and 1", len(*data), api.requests())
+	}
+}
+
+func TestFailoverAfterServerError(t *testing.T) {
+	failing := newFakeAPI(t, respondJSON(http.StatusServiceUnavailable, `{"errors":[{"message":"down"}]}`))
+	healthy := newFakeAPI(t, echoResults)
+	client := NewGenerateImagesV1(testAPIKey, WithEndpoints([]string{failing.URL, healthy.URL}))
+	if _, err := client.Config([]map[string]any{testTask(nil)}).GenerateV1(); err != nil {
+		t.Fatalf("GenerateV1() = %v, want the second endpoint to answer", err)
+	}
+	if failing.requests() != 1 || healthy.requests() != 1 {
+		t.Errorf("requests = %d to the failing endpoint and %d to the healthy one, want 1 and 1", failing.requests(), healthy.requests())
+	}
+	if got, want := client.LastResponseMeta().Endpoint, healthy.URL+"/"+DefaultAPIVersion; got != want {
+		t.Errorf("LastResponseMeta().Endpoint = %q, want %q", got, want)
+	}
+}
+
+func TestNoFailoverAfterClientError(t *testing.T) {
+	rejecting := newFakeAPI(t, respondJSON(http.StatusBadRequest, `{"errors":[{"code":"invalidPrompt","message":"bad prompt"}]}`))
+	other := newFakeAPI(t, echoResults)
+	client := NewGenerateImagesV1(testAPIKey, WithEndpoints([]string{rejecting.URL, other.URL}))
+	if _, err := client.Config([]map[string]any{testTask(nil)}).GenerateV1(); err == nil {
+		t.Fatal("GenerateV1() succeeded, want the 400 returned")
+	}
+	if other.requests() != 0 {
+		t.Errorf("a 400 failed over to the next endpoint")
+	}
+	if got, want := client.LastResponseMeta().Endpoint, rejecting.URL+"/"+DefaultAPIVersion; got != want {
+		t.Errorf("LastResponseMeta().Endpoint = %q, want %q", got, want)
+	}
+}
+
+func TestFailedEndpointCooldown(t *testing.T) {
+	const cooldown = 100 * time.Millisecond
+	first := newFakeAPI(t, respondInTurn(respondJSON(http.StatusBadGateway, `{"errors":[{"message":"down"}]}`), echoResults))
+	second := newFakeAPI(t, echoResults)
+	client := NewGenerateImagesV1(testAPIKey, WithEndpoints([]string{first.URL, second.URL}), WithEndpointCooldown(cooldown))
+	client.Config([]map[string]any{testTask(nil)})
+	generate := func(wantFirst, wantSecond int) {
+		t.Helper()
+		if _, err := client.GenerateV1(); err != nil {
+			t.Fatalf("GenerateV1() = %v", err)
+		}
+		if first.requests() != wantFirst || second.requests() != wantSecond {
+			t.Fatalf("requests = %d to the first endpoint and %d to the second, want %d and %d",
+				first.requests(), second.requests(), wantFirst, wantSecond)
+		}
+	}
+	generate(1, 1)
+	// Cooling down: the first endpoint is skipped.
+	generate(1, 2)
+	time.Sleep(cooldown + 50*time.Millisecond)
+	// Cooled down: the first endpoint is tried again, and answers.
+	generate(2, 2)
+	if got, want := client.LastResponseMeta().Endpoint, first.URL+"/"+DefaultAPIVersion; got != want {
+		t.Errorf("LastResponseMeta().Endpoint = %q, want %q", got, want)
+	}
+}
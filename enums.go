@@ -0,0 +1,100 @@
+package runware
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+var (
+	// knownTaskTypes is the registry of task types this package knows how
+	// to build a payload for.
+	knownTaskTypes     = []TaskType{ImageInference, ImageUpscale, ImageBackgroundRemoval, PhotoMaker}
+	knownOutputTypes   = []OutputType{Base64Data, DataURI, URL}
+	knownOutputFormats = []OutputFormat{PNG, JPG, WEBP}
+)
+
+func (t TaskType) String() string { return string(t) }
+
+// IsValid reports whether t is a task type this package knows.
+func (t TaskType) IsValid() bool { return slices.Contains(knownTaskTypes, t) }
+
+// TaskTypeValues returns every known TaskType.
+func TaskTypeValues() []TaskType { return slices.Clone(knownTaskTypes) }
+
+// ParseTaskType returns the TaskType named by s, ignoring case.
+func ParseTaskType(s string) (TaskType, error) {
+	if t := normalizeTaskType(s); t.IsValid() {
+		return t, nil
+	}
+	return "", fmt.Errorf("unknown taskType %q, expected one of %v", s, knownTaskTypes)
+}
+
+func (t OutputType) String() string { return string(t) }
+
+// IsValid reports whether t is a known output type.
+func (t OutputType) IsValid() bool { return slices.Contains(knownOutputTypes, t) }
+
+// OutputTypeValues returns every known OutputType.
+func OutputTypeValues() []OutputType { return slices.Clone(knownOutputTypes) }
+
+// ParseOutputType returns the OutputType named by s, ignoring case.
+func ParseOutputType(s string) (OutputType, error) {
+	if t := normalizeOutputType(s); t.IsValid() {
+		return t, nil
+	}
+	return "", fmt.Errorf("unknown outputType %q, expected one of %v", s, knownOutputTypes)
+}
+
+func (f OutputFormat) String() string { return string(f) }
+
+// IsValid reports whether f is a known output format.
+func (f OutputFormat) IsValid() bool { return slices.Contains(knownOutputFormats, f) }
+
+// OutputFormatValues returns every known OutputFormat.
+func OutputFormatValues() []OutputFormat { return slices.Clone(knownOutputFormats) }
+
+// ParseOutputFormat returns the OutputFormat named by s, ignoring case and
+// accepting "jpg" for JPG.
+func ParseOutputFormat(s string) (OutputFormat, error) {
+	if f := normalizeOutputFormat(s); f.IsValid() {
+		return f, nil
+	}
+	return "", fmt.Errorf("unknown outputFormat %q, expected one of %v", s, knownOutputFormats)
+}
+
+// normalizeTaskType maps a case-insensitive task type name to its constant.
+// Unknown values are returned unchanged.
+func normalizeTaskType(value string) TaskType {
+	for _, known := range knownTaskTypes {
+		if strings.EqualFold(value, string(known)) {
+			return known
+		}
+	}
+	return TaskType(value)
+}
+
+// normalizeOutputType maps a case-insensitive output type name to its
+// constant. Unknown values are returned unchanged.
+func normalizeOutputType(value string) OutputType {
+	for _, known := range knownOutputTypes {
+		if strings.EqualFold(value, string(known)) {
+			return known
+		}
+	}
+	return OutputType(value)
+}
+
+// normalizeOutputFormat maps a case-insensitive output format name, including
+// the "jpg" alias, to its constant. Unknown values are returned unchanged.
+func normalizeOutputFormat(value string) OutputFormat {
+	if strings.EqualFold(value, "jpg") {
+		return JPG
+	}
+	for _, known := range knownOutputFormats {
+		if strings.EqualFold(value, string(known)) {
+			return known
+		}
+	}
+	return OutputFormat(value)
+}
@@ -0,0 +1,410 @@
+package runware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrorCode is a machine-readable error code returned by the API. It
+// implements error so that the known codes can be used with errors.Is; codes
+// not listed here are preserved as is.
+type ErrorCode string
+
+const (
+	ErrInvalidAPIKey       ErrorCode = "invalidApiKey"
+	ErrInvalidParameter    ErrorCode = "invalidParameter"
+	ErrInsufficientCredits ErrorCode = "insufficientCredits"
+	ErrUnsupportedModel    ErrorCode = "unsupportedModel"
+	ErrNSFWContent         ErrorCode = "nsfwContent"
+	ErrRateLimitExceeded   ErrorCode = "rateLimitExceeded"
+	ErrInvalidDimensions   ErrorCode = "invalidDimensions"
+)
+
+func (c ErrorCode) Error() string { return string(c) }
+
+// ErrNoResults is matched, through errors.Is, by the *NoResultsError returned
+// when the API reports success but sends back no results.
+var ErrNoResults = errors.New("no results returned")
+
+// NoResultsError is returned for a successful response without any results,
+// e.g. because every image was filtered out. Errors holds whatever error
+// entries came back with it, often explaining why.
+type NoResultsError struct {
+	Errors []RunwareErrorResponseBody
+	// Meta describes the response, including its request ID.
+	Meta *ResponseMeta
+	// CorrelationID is the ID sent in the X-Correlation-Id header.
+	CorrelationID string
+}
+
+func (e *NoResultsError) Error() string {
+	msg := ErrNoResults.Error()
+	if e.Meta != nil && e.Meta.RequestID != "" {
+		msg += fmt.Sprintf(" (request ID %s)", e.Meta.RequestID)
+	}
+	if e.CorrelationID != "" {
+		msg += fmt.Sprintf(" (correlation ID %s)", e.CorrelationID)
+	}
+	if len(e.Errors) == 0 {
+		return msg
+	}
+	jsonDataErrResponse, err := json.MarshalIndent(e.Errors, "", "  ")
+	if err != nil {
+		return msg
+	}
+	return fmt.Sprintf("%s: %s", msg, jsonDataErrResponse)
+}
+
+func (e *NoResultsError) Is(target error) bool { return target == ErrNoResults }
+
+// ErrUnauthorized matches, through errors.Is, a *RunwareAPIError for a 401
+// response: the API key is missing, invalid or expired and should be replaced.
+// Such errors are never retried.
+var ErrUnauthorized = errors.New("unauthorized")
+
+// ErrForbidden matches, through errors.Is, a *RunwareAPIError for a 403
+// response: the key is valid but not allowed to make this request, for
+// example to use the model. Such errors are never retried.
+var ErrForbidden = errors.New("forbidden")
+
+// ErrorCategory groups error codes by what the caller should do about them.
+type ErrorCategory int
+
+const (
+	CategoryUnknown ErrorCategory = iota
+	// CategoryAuth: the API key is missing or invalid.
+	CategoryAuth
+	// CategoryBilling: the account cannot pay for the request.
+	CategoryBilling
+	// CategoryValidation: the request itself must be fixed.
+	CategoryValidation
+	// CategoryContent: the content was blocked by moderation.
+	CategoryContent
+	// CategoryRateLimit: the request may succeed later.
+	CategoryRateLimit
+)
+
+func (c ErrorCategory) String() string {
+	switch c {
+	case CategoryAuth:
+		return "auth"
+	case CategoryBilling:
+		return "billing"
+	case CategoryValidation:
+		return "validation"
+	case CategoryContent:
+		return "content"
+	case CategoryRateLimit:
+		return "rateLimit"
+	default:
+		return "unknown"
+	}
+}
+
+// Category classifies the code. Codes this package does not know are
+// CategoryUnknown.
+func (c ErrorCode) Category() ErrorCategory {
+	switch c {
+	case ErrInvalidAPIKey:
+		return CategoryAuth
+	case ErrInsufficientCredits:
+		return CategoryBilling
+	case ErrInvalidParameter, ErrUnsupportedModel, ErrInvalidDimensions:
+		return CategoryValidation
+	case ErrNSFWContent:
+		return CategoryContent
+	case ErrRateLimitExceeded:
+		return CategoryRateLimit
+	default:
+		return CategoryUnknown
+	}
+}
+
+// IsInsufficientCredits reports whether err was caused by a lack of credits.
+func IsInsufficientCredits(err error) bool { return errors.Is(err, ErrInsufficientCredits) }
+
+// IsInvalidModel reports whether err was caused by an unknown or unsupported
+// model.
+func IsInvalidModel(err error) bool { return errors.Is(err, ErrUnsupportedModel) }
+
+// IsNSFWBlocked reports whether err was caused by content moderation.
+func IsNSFWBlocked(err error) bool { return errors.Is(err, ErrNSFWContent) }
+
+// IsInvalidDimensions reports whether the API rejected the width or height.
+func IsInvalidDimensions(err error) bool { return errors.Is(err, ErrInvalidDimensions) }
+
+// IsRateLimited reports whether err was caused by rate limiting, either by
+// error code or by a 429 response.
+func IsRateLimited(err error) bool {
+	var rateErr *RateLimitedError
+	return errors.Is(err, ErrRateLimitExceeded) || errors.As(err, &rateErr)
+}
+
+// RunwareAPIError is returned when the API answers with a non-2xx status.
+// Errors is empty when the response body was missing or could not be decoded.
+type RunwareAPIError struct {
+	StatusCode int
+	Errors     []RunwareErrorResponseBody
+	// Body holds at most the first 1KB of a response body that was not JSON,
+	// such as an HTML error page from a proxy.
+	Body string
+	// Meta describes the failed response, including its request ID.
+	Meta *ResponseMeta
+	// CorrelationID is the ID sent in the X-Correlation-Id header, either
+	// given through WithCorrelationID or generated for the call.
+	CorrelationID string
+}
+
+func (e *RunwareAPIError) Error() string {
+	status := fmt.Sprintf("request failed with status %d", e.StatusCode)
+	if e.Meta != nil && e.Meta.RequestID != "" {
+		status += fmt.Sprintf(" (request ID %s)", e.Meta.RequestID)
+	}
+	if e.CorrelationID != "" {
+		status += fmt.Sprintf(" (correlation ID %s)", e.CorrelationID)
+	}
+	if len(e.Errors) == 0 {
+		if e.Body != "" {
+			return fmt.Sprintf("%s: %s", status, e.Body)
+		}
+		return status
+	}
+	jsonDataErrResponse, err := json.MarshalIndent(e.Errors, "", "  ")
+	if err != nil {
+		return status
+	}
+	return fmt.Sprintf("%s: %s", status, jsonDataErrResponse)
+}
+
+// Code returns the code of the first error reported by the API, or "" when
+// there is none.
+func (e *RunwareAPIError) Code() ErrorCode {
+	if len(e.Errors) == 0 {
+		return ""
+	}
+	return e.Errors[0].Code
+}
+
+// Is makes errors.Is(err, runware.ErrInsufficientCredits) and the like match
+// when any of the reported errors carries that code, and ErrUnauthorized and
+// ErrForbidden match 401 and 403 responses.
+func (e *RunwareAPIError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	}
+	code, ok := target.(ErrorCode)
+	if !ok {
+		return false
+	}
+	for _, apiErr := range e.Errors {
+		if apiErr.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// redactErrors removes any echo of the API key from the messages the API
+// returned, so that logging the error never leaks it.
+func redactErrors(errs []RunwareErrorResponseBody, apiKey string) []RunwareErrorResponseBody {
+	for i := range errs {
+		errs[i].Message = redactKey(errs[i].Message, apiKey)
+		errs[i].Parameter = redactKey(errs[i].Parameter, apiKey)
+	}
+	return errs
+}
+
+// maxBodySnippet bounds how much of an unexpected response body is kept for
+// error messages.
+const maxBodySnippet = 1024
+
+// UnexpectedResponseError is returned when the API, or something in front of
+// it, answers a successful status with a body that is not JSON, such as an
+// HTML page. A failed status with such a body is a *RunwareAPIError.
+type UnexpectedResponseError struct {
+	StatusCode  int
+	ContentType string
+	// Body holds at most the first 1KB of the response body.
+	Body string
+}
+
+func (e *UnexpectedResponseError) Error() string {
+	return fmt.Sprintf("unexpected %q response with status %d: %s", e.ContentType, e.StatusCode, e.Body)
+}
+
+// DecodeError is returned, wrapped in a *TransportError, when a JSON response
+// could not be decoded. Body holds the start of what the server sent, to tell
+// a truncated response from a schema change or a misconfigured proxy. A
+// response cut short (io.ErrUnexpectedEOF) matches ErrConnection and is
+// retried like a dropped connection; malformed JSON is not retried.
+type DecodeError struct {
+	StatusCode int
+	// Body holds at most the first 1KB of the response body.
+	Body string
+	Err  error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("failed to decode response with status %d: %v; body starts with %q", e.StatusCode, e.Err, e.Body)
+}
+
+func (e *DecodeError) Unwrap() error { return e.Err }
+
+// snippetReader passes r through, keeping its first maxBodySnippet bytes for
+// error messages.
+type snippetReader struct {
+	r    io.Reader
+	head []byte
+}
+
+func (s *snippetReader) Read(p []byte) (int, error) {
+	n, err := s.r.Read(p)
+	if room := maxBodySnippet - len(s.head); room > 0 {
+		s.head = append(s.head, p[:min(n, room)]...)
+	}
+	return n, err
+}
+
+func newUnexpectedResponseError(resp *http.Response, apiKey string) *UnexpectedResponseError {
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, maxBodySnippet))
+	return &UnexpectedResponseError{
+		StatusCode:  resp.StatusCode,
+		ContentType: resp.Header.Get("Content-Type"),
+		Body:        redactKey(string(snippet), apiKey),
+	}
+}
+
+// RateLimitedError is returned when the API answers 429 Too Many Requests.
+// RetryAfter holds the wait requested by the Retry-After header, or zero when
+// the header was missing or malformed.
+type RateLimitedError struct {
+	RetryAfter time.Duration
+	Err        *RunwareAPIError
+}
+
+func (e *RateLimitedError) Error() string {
+	return fmt.Sprintf("rate limited, retry after %s: %v", e.RetryAfter, e.Err)
+}
+
+func (e *RateLimitedError) Unwrap() error { return e.Err }
+
+// parseRetryAfter parses a Retry-After header in either its delta-seconds or
+// HTTP-date form.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	return max(time.Until(date), 0), true
+}
+
+// maxErrorTaskUUIDs bounds how many task UUIDs a TransportError lists.
+const maxErrorTaskUUIDs = 3
+
+// ErrTimeout matches, through errors.Is, failures caused by a deadline or a
+// network timeout: a *TransportError whose request timed out, and a
+// *TaskTimeoutError.
+var ErrTimeout = errors.New("request timed out")
+
+// ErrConnection matches, through errors.Is, a *TransportError caused by a
+// connection-level failure other than a timeout: DNS errors, refused or reset
+// connections, and responses cut off before they were complete.
+var ErrConnection = errors.New("connection failed")
+
+// TransportError is returned when talking to the API failed (connection,
+// timeout, reading the response) rather than the API rejecting the request.
+// Timeouts and connection failures match ErrTimeout and ErrConnection and are
+// safe to retry. The underlying error is available through errors.Unwrap.
+type TransportError struct {
+	URL       string
+	TaskUUIDs []string
+	// Attempt is the 1-based attempt number, or zero when retries are off.
+	Attempt       int
+	CorrelationID string
+	Err           error
+}
+
+func (e *TransportError) Error() string {
+	var details []string
+	if uuids := e.TaskUUIDs; len(uuids) > 0 {
+		if len(uuids) > maxErrorTaskUUIDs {
+			uuids = append(slices.Clip(uuids[:maxErrorTaskUUIDs]), "...")
+		}
+		details = append(details, fmt.Sprintf("%d tasks: %s", len(e.TaskUUIDs), strings.Join(uuids, ", ")))
+	}
+	if e.Attempt > 0 {
+		details = append(details, fmt.Sprintf("attempt %d", e.Attempt))
+	}
+	if e.CorrelationID != "" {
+		details = append(details, fmt.Sprintf("correlation ID %s", e.CorrelationID))
+	}
+	msg := fmt.Sprintf("request to %s failed", e.URL)
+	if len(details) > 0 {
+		msg += " (" + strings.Join(details, ", ") + ")"
+	}
+	return fmt.Sprintf("%s: %v", msg, e.Err)
+}
+
+func (e *TransportError) Unwrap() error { return e.Err }
+
+func (e *TransportError) Is(target error) bool {
+	switch target {
+	case ErrTimeout:
+		return isTimeout(e.Err)
+	case ErrConnection:
+		return isConnectionError(e.Err)
+	}
+	return false
+}
+
+// isTimeout reports whether err was caused by a deadline or network timeout.
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, context.DeadlineExceeded) || errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// isConnectionError reports whether err means no complete response was
+// received, for a reason other than a timeout or the caller canceling.
+func isConnectionError(err error) bool {
+	if isTimeout(err) || errors.Is(err, context.Canceled) {
+		return false
+	}
+	// client.Do only returns *url.Error, and only when no response arrived.
+	var urlErr *url.Error
+	var opErr *net.OpError
+	return errors.As(err, &urlErr) || errors.As(err, &opErr) ||
+		errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET)
+}
+
+func newTransportError(g *generateImagesV1Impl, req *http.Request, err error) *TransportError {
+	uuids := make([]string, len(g.options))
+	for i, option := range g.options {
+		uuids[i] = option.TaskUUID
+	}
+	correlationID, _ := CorrelationID(req.Context())
+	return &TransportError{URL: req.URL.String(), TaskUUIDs: uuids, CorrelationID: correlationID, Err: &redactedError{err: err, apiKey: g.apiKey}}
+}
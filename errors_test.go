@@ -0,0 +1,257 @@
+package runware
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// respondText answers with status and body under contentType.
+func respondText(status int, contentType, body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(status)
+		io.WriteString(w, body)
+	}
+}
+
+func TestDoRequestMapsStatusBeforeContentType(t *testing.T) {
+	tests := []struct {
+		name      string
+		respond   http.HandlerFunc
+		is        error
+		retryable bool
+		check     func(t *testing.T, err error)
+	}{
+		{
+			name:      "429 html",
+			respond:   withHeaders(respondText(http.StatusTooManyRequests, "text/html", "<html>slow down</html>"), "Retry-After", "7"),
+			retryable: true,
+			check: func(t *testing.T, err error) {
+				if !IsRateLimited(err) {
+					t.Errorf("IsRateLimited(%v) = false", err)
+				}
+				var rateErr *RateLimitedError
+				if !errors.As(err, &rateErr) || rateErr.RetryAfter != 7*time.Second {
+					t.Errorf("err = %#v, want a *RateLimitedError with RetryAfter 7s", err)
+				}
+			},
+		},
+		{name: "401 plain text", respond: respondText(http.StatusUnauthorized, "text/plain", "bad key"), is: ErrUnauthorized},
+		{name: "403 plain text", respond: respondText(http.StatusForbidden, "text/plain", "no"), is: ErrForbidden},
+		{name: "401 json", respond: respondJSON(http.StatusUnauthorized, `{"errors":[{"code":"invalidApiKey","message":"bad key"}]}`), is: ErrUnauthorized},
+		{
+			name:      "502 html",
+			respond:   respondText(http.StatusBadGateway, "text/html", "<html>bad gateway</html>"),
+			retryable: true,
+			check: func(t *testing.T, err error) {
+				var apiErr *RunwareAPIError
+				if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadGateway || apiErr.Body != "<html>bad gateway</html>" {
+					t.Errorf("err = %#v, want a *RunwareAPIError for 502 carrying the body", err)
+				}
+			},
+		},
+		{
+			name:    "200 html",
+			respond: respondText(http.StatusOK, "text/html", "<html>portal</html>"),
+			check: func(t *testing.T, err error) {
+				var unexpected *UnexpectedResponseError
+				if !errors.As(err, &unexpected) || unexpected.Body != "<html>portal</html>" {
+					t.Errorf("err = %#v, want an *UnexpectedResponseError", err)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newFakeAPI(t, tt.respond)
+			_, err := api.client().Config([]map[string]any{testTask(nil)}).GenerateV1()
+			if err == nil {
+				t.Fatal("GenerateV1 succeeded")
+			}
+			if tt.is != nil && !errors.Is(err, tt.is) {
+				t.Errorf("err = %v, want it to match %v", err, tt.is)
+			}
+			if got := IsRetryable(err); got != tt.retryable {
+				t.Errorf("IsRetryable(%v) = %v, want %v", err, got, tt.retryable)
+			}
+			if tt.check != nil {
+				tt.check(t, err)
+			}
+		})
+	}
+}
+
+func TestDoRequestNonJSONBodies(t *testing.T) {
+	tests := []struct {
+		name    string
+		respond http.HandlerFunc
+		check   func(t *testing.T, err error)
+	}{
+		{
+			name:    "html 503",
+			respond: respondText(http.StatusServiceUnavailable, "text/html", "<html><body>503 Service Unavailable</body></html>"),
+			check: func(t *testing.T, err error) {
+				var apiErr *RunwareAPIError
+				if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable || !strings.Contains(apiErr.Body, "503 Service Unavailable") {
+					t.Errorf("err = %v, want a *RunwareAPIError with the status and body", err)
+				}
+			},
+		},
+		{
+			name:    "empty 502",
+			respond: respondText(http.StatusBadGateway, "", ""),
+			check: func(t *testing.T, err error) {
+				var apiErr *RunwareAPIError
+				if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadGateway {
+					t.Errorf("err = %v, want a *RunwareAPIError for 502", err)
+				}
+			},
+		},
+		{
+			name:    "large html 200",
+			respond: respondText(http.StatusOK, "text/html", strings.Repeat("x", 4*maxBodySnippet)),
+			check: func(t *testing.T, err error) {
+				var unexpected *UnexpectedResponseError
+				if !errors.As(err, &unexpected) || unexpected.StatusCode != http.StatusOK || len(unexpected.Body) != maxBodySnippet {
+					t.Errorf("err = %v, want an *UnexpectedResponseError with the first %d bytes", err, maxBodySnippet)
+				}
+			},
+		},
+		{
+			name:    "truncated json",
+			respond: respondJSON(http.StatusOK, `{"data":[{"taskType":"imageInference","imageUUID":"ab`),
+			check: func(t *testing.T, err error) {
+				var decodeErr *DecodeError
+				if !errors.As(err, &decodeErr) || decodeErr.StatusCode != http.StatusOK || !strings.HasPrefix(decodeErr.Body, `{"data":[`) {
+					t.Errorf("err = %v, want a *DecodeError with the status and start of the body", err)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newFakeAPI(t, tt.respond)
+			_, err := api.client().Config([]map[string]any{testTask(nil)}).GenerateV1()
+			if err == nil {
+				t.Fatal("GenerateV1 succeeded")
+			}
+			if strings.Contains(err.Error(), "invalid character") && !strings.Contains(err.Error(), "status") {
+				t.Errorf("err = %v, want the status code in the message", err)
+			}
+			tt.check(t, err)
+		})
+	}
+}
+
+func TestFailedStatusIsAlwaysAnError(t *testing.T) {
+	tests := []struct {
+		name     string
+		respond  http.HandlerFunc
+		status   int
+		wantCode ErrorCode
+	}{
+		{"400 with errors", respondJSON(http.StatusBadRequest, `{"errors":[{"code":"invalidPositivePrompt","message":"too short","parameter":"positivePrompt"}]}`), http.StatusBadRequest, "invalidPositivePrompt"},
+		{"400 with empty body", respondJSON(http.StatusBadRequest, ""), http.StatusBadRequest, ""},
+		{"400 with empty object", respondJSON(http.StatusBadRequest, "{}"), http.StatusBadRequest, ""},
+		{"500 with garbage", respondJSON(http.StatusInternalServerError, "}{not json"), http.StatusInternalServerError, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newFakeAPI(t, tt.respond)
+			results, err := api.client().Config([]map[string]any{testTask(nil)}).GenerateV1()
+			var apiErr *RunwareAPIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("GenerateV1 = %v, %v; want a *RunwareAPIError", results, err)
+			}
+			if apiErr.StatusCode != tt.status || apiErr.Code() != tt.wantCode {
+				t.Errorf("status %d code %q, want %d %q", apiErr.StatusCode, apiErr.Code(), tt.status, tt.wantCode)
+			}
+			if !strings.Contains(err.Error(), strconv.Itoa(tt.status)) {
+				t.Errorf("err = %v, want the status code in the message", err)
+			}
+		})
+	}
+}
+
+func TestAuthErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		is     error
+		isNot  error
+	}{
+		{"unauthorized", http.StatusUnauthorized, ErrUnauthorized, ErrForbidden},
+		{"forbidden", http.StatusForbidden, ErrForbidden, ErrUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The API echoes the key back; the error must not.
+			api := newFakeAPI(t, respondJSON(tt.status, `{"errors":[{"code":"accessDenied","message":"key `+testAPIKey+` may not use this model"}]}`))
+			_, err := api.client(WithRetries(3, 0)).Config([]map[string]any{testTask(nil)}).GenerateV1()
+			if !errors.Is(err, tt.is) || errors.Is(err, tt.isNot) {
+				t.Errorf("err = %v, want it to match %v and not %v", err, tt.is, tt.isNot)
+			}
+			if api.requests() != 1 {
+				t.Errorf("server got %d requests, want no retries", api.requests())
+			}
+			if !strings.Contains(err.Error(), "may not use this model") {
+				t.Errorf("err = %v, want the API's message", err)
+			}
+			if strings.Contains(err.Error(), testAPIKey) {
+				t.Errorf("err = %v echoes the API key", err)
+			}
+		})
+	}
+}
+
+func TestEmptyDataIsNoResults(t *testing.T) {
+	for _, body := range []string{`{"data":[]}`, `{}`, `{"data":[],"errors":[]}`} {
+		api := newFakeAPI(t, respondJSON(http.StatusOK, body))
+		results, err := api.client().Config([]map[string]any{testTask(nil)}).GenerateV1()
+		if !errors.Is(err, ErrNoResults) {
+			t.Errorf("%s: err = %v, want ErrNoResults", body, err)
+		}
+		if results != nil && len(*results) != 0 {
+			t.Errorf("%s: results = %+v, want none", body, *results)
+		}
+	}
+}
+
+func TestTransportErrorUnwraps(t *testing.T) {
+	endpoint := refusedEndpoint(t)
+	client := NewGenerateImagesV1(testAPIKey, WithEndpoints([]string{endpoint}), WithRetries(1, time.Millisecond))
+	_, err := client.Config([]map[string]any{testTask(map[string]any{"taskUUID": "0b4c2f8e-transport"})}).GenerateV1()
+
+	var tErr *TransportError
+	if !errors.As(err, &tErr) {
+		t.Fatalf("GenerateV1() = %v, want a *TransportError", err)
+	}
+	var redacted *redactedError
+	if !errors.As(tErr.Err, &redacted) {
+		t.Fatalf("TransportError.Err = %T, want the key redacted", tErr.Err)
+	}
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) || urlErr.URL != endpoint+"/"+DefaultAPIVersion {
+		t.Errorf("errors.As(*url.Error) through TransportError and redactedError = %v", urlErr)
+	}
+	var opErr *net.OpError
+	if !errors.As(err, &opErr) || !errors.Is(err, syscall.ECONNREFUSED) {
+		t.Errorf("GenerateV1() = %v, want the refused dial reachable", err)
+	}
+	if !errors.Is(err, ErrConnection) {
+		t.Errorf("GenerateV1() = %v, want it to match ErrConnection", err)
+	}
+	for _, want := range []string{"request to " + endpoint + "/" + DefaultAPIVersion + " failed", "1 tasks: 0b4c2f8e-transport", "attempt 2"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Error() = %q, want it to contain %q", err.Error(), want)
+		}
+	}
+}
@@ -0,0 +1,261 @@
+package runware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// resultFields maps the JSON name of each field of RunwareSuccessResponseBody,
+// as declared and lower-cased since encoding/json matches names
+// case-insensitively, to the field's index.
+var resultFields = func() map[string]int {
+	fields := make(map[string]int)
+	t := reflect.TypeOf(RunwareSuccessResponseBody{})
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			fields[name] = i
+			fields[strings.ToLower(name)] = i
+		}
+	}
+	return fields
+}()
+
+// seedField is the index of Seed, which is decoded by flexibleInt64.
+var seedField = resultFields["seed"]
+
+// UnmarshalJSON decodes a result and keeps any fields the SDK does not know
+// about in Extra. The object is walked once, member by member, rather than
+// decoded once into the struct and again into a map: with a large base64
+// image the second pass doubled the decoding time. Strings without escapes,
+// such as the image data, are copied straight from the input.
+func (r *RunwareSuccessResponseBody) UnmarshalJSON(data []byte) error {
+	if string(bytes.TrimSpace(data)) == "null" {
+		return nil
+	}
+	result := reflect.ValueOf(r).Elem()
+	return eachMember(data, func(key []byte, value []byte) error {
+		index, known := resultFields[string(key)]
+		if !known {
+			index, known = resultFields[strings.ToLower(string(key))]
+		}
+		if !known {
+			if r.Extra == nil {
+				r.Extra = make(map[string]json.RawMessage)
+			}
+			r.Extra[string(key)] = append(json.RawMessage(nil), value...)
+			return nil
+		}
+		if index == seedField {
+			var seed flexibleInt64
+			if err := seed.UnmarshalJSON(value); err != nil {
+				return fmt.Errorf("field %s: %w", key, err)
+			}
+			r.Seed = int64(seed)
+			return nil
+		}
+		if err := setField(result.Field(index), value); err != nil {
+			return fmt.Errorf("field %s: %w", key, err)
+		}
+		return nil
+	})
+}
+
+// setField decodes value into field, parsing the plain strings, booleans and
+// numbers results are made of directly and leaving anything else to
+// encoding/json.
+func setField(field reflect.Value, value []byte) error {
+	switch field.Kind() {
+	case reflect.String:
+		if isPlainString(value) {
+			field.SetString(string(value[1 : len(value)-1]))
+			return nil
+		}
+	case reflect.Bool:
+		switch string(value) {
+		case "true", "false":
+			field.SetBool(string(value) == "true")
+			return nil
+		}
+	case reflect.Float64:
+		if f, err := strconv.ParseFloat(string(value), 64); err == nil {
+			field.SetFloat(f)
+			return nil
+		}
+	case reflect.Uint16:
+		if n, err := strconv.ParseUint(string(value), 10, 16); err == nil {
+			field.SetUint(n)
+			return nil
+		}
+	}
+	return json.Unmarshal(value, field.Addr().Interface())
+}
+
+var errMalformedObject = errors.New("malformed JSON object")
+
+// eachMember calls fn with the key and raw value of every member of the JSON
+// object in data. encoding/json validates its input before calling
+// UnmarshalJSON, so this only splits it, but input that is not an object is
+// still reported rather than trusted.
+func eachMember(data []byte, fn func(key, value []byte) error) error {
+	i := skipSpace(data, 0)
+	if i >= len(data) || data[i] != '{' {
+		return fmt.Errorf("cannot decode %.20q into a result: expected an object", data)
+	}
+	if i = skipSpace(data, i+1); i < len(data) && data[i] == '}' {
+		return nil
+	}
+	for {
+		if i >= len(data) || data[i] != '"' {
+			return errMalformedObject
+		}
+		end := stringEnd(data, i)
+		if end < 0 {
+			return errMalformedObject
+		}
+		key := data[i+1 : end-1]
+		if !isPlainString(data[i:end]) {
+			var unquoted string
+			if err := json.Unmarshal(data[i:end], &unquoted); err != nil {
+				return err
+			}
+			key = []byte(unquoted)
+		}
+		if i = skipSpace(data, end); i >= len(data) || data[i] != ':' {
+			return errMalformedObject
+		}
+		i = skipSpace(data, i+1)
+		if end = valueEnd(data, i); end < 0 {
+			return errMalformedObject
+		}
+		if err := fn(key, data[i:end]); err != nil {
+			return err
+		}
+		if i = skipSpace(data, end); i >= len(data) {
+			return errMalformedObject
+		}
+		switch data[i] {
+		case ',':
+			i = skipSpace(data, i+1)
+		case '}':
+			return nil
+		default:
+			return errMalformedObject
+		}
+	}
+}
+
+func skipSpace(data []byte, i int) int {
+	for i < len(data) && (data[i] == ' ' || data[i] == '\t' || data[i] == '\n' || data[i] == '\r') {
+		i++
+	}
+	return i
+}
+
+// stringEnd returns the index just past the JSON string starting at data[i],
+// or -1 if it is not terminated.
+func stringEnd(data []byte, i int) int {
+	for j := i + 1; ; {
+		quote := bytes.IndexByte(data[j:], '"')
+		if quote < 0 {
+			return -1
+		}
+		j += quote
+		// The quote is escaped if an odd number of backslashes precede it.
+		backslashes := 0
+		for k := j - 1; k > i && data[k] == '\\'; k-- {
+			backslashes++
+		}
+		if backslashes%2 == 0 {
+			return j + 1
+		}
+		j++
+	}
+}
+
+// valueEnd returns the index just past the JSON value starting at data[i], or
+// -1 if it is not terminated.
+func valueEnd(data []byte, i int) int {
+	if i >= len(data) {
+		return -1
+	}
+	switch data[i] {
+	case '"':
+		return stringEnd(data, i)
+	case '{', '[':
+		depth := 0
+		for j := i; j < len(data); j++ {
+			switch data[j] {
+			case '"':
+				end := stringEnd(data, j)
+				if end < 0 {
+					return -1
+				}
+				j = end - 1
+			case '{', '[':
+				depth++
+			case '}', ']':
+				if depth--; depth == 0 {
+					return j + 1
+				}
+			}
+		}
+		return -1
+	default:
+		j := i
+		for j < len(data) && !strings.ContainsRune(",}] \t\n\r", rune(data[j])) {
+			j++
+		}
+		return j
+	}
+}
+
+// isPlainString reports whether value is a JSON string that decodes to its
+// bytes as they are: no escapes and valid UTF-8.
+func isPlainString(value []byte) bool {
+	return len(value) >= 2 && value[0] == '"' && bytes.IndexByte(value, '\\') < 0 && utf8.Valid(value)
+}
+
+// flexibleInt64 decodes an integer given either as a JSON number or as a
+// string, which APIs use to keep 64-bit values exact in JavaScript clients.
+type flexibleInt64 int64
+
+func (f *flexibleInt64) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	text := string(data)
+	if unquoted, err := strconv.Unquote(text); err == nil {
+		text = unquoted
+	}
+	n, err := strconv.ParseInt(text, 10, 64)
+	if err != nil {
+		return fmt.Errorf("expected an integer, got %s: %w", data, err)
+	}
+	*f = flexibleInt64(n)
+	return nil
+}
+
+// warnUnknownFields logs each field found in Extra once per response.
+func warnUnknownFields(results []RunwareSuccessResponseBody) {
+	var fields []string
+	for _, r := range results {
+		for key := range r.Extra {
+			if !slices.Contains(fields, key) {
+				fields = append(fields, key)
+			}
+		}
+	}
+	slices.Sort(fields)
+	for _, key := range fields {
+		log.Printf("warning: response field %q is not known to this SDK", key)
+	}
+}
@@ -0,0 +1,127 @@
+package runware
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestUnmarshalResult(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want RunwareSuccessResponseBody
+	}{
+		{
+			name: "all fields",
+			json: `{"taskType":"imageInference","taskUUID":"t","imageUUID":"i","imageURL":"https://im.runware.ai/i.jpg",
+				"seed":"9007199254740993","cost":0.0013,"NSFWContent":true,"cached":true,"width":1024,"height":576}`,
+			want: RunwareSuccessResponseBody{
+				TaskType: "imageInference", TaskUUID: "t", ImageUUID: "i", ImageUrl: "https://im.runware.ai/i.jpg",
+				Seed: 9007199254740993, Cost: 0.0013, NSFWContent: true, Cached: true, Width: 1024, Height: 576,
+			},
+		},
+		{
+			name: "dimensions absent",
+			json: `{"taskType":"imageInference","imageUUID":"i","seed":42}`,
+			want: RunwareSuccessResponseBody{TaskType: "imageInference", ImageUUID: "i", Seed: 42},
+		},
+		{
+			name: "unknown fields",
+			json: `{"imageUUID":"i","status":"success","newField":{"a":[1,2]}}`,
+			want: RunwareSuccessResponseBody{ImageUUID: "i", Extra: map[string]json.RawMessage{
+				"status":   json.RawMessage(`"success"`),
+				"newField": json.RawMessage(`{"a":[1,2]}`),
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got RunwareSuccessResponseBody
+			if err := json.Unmarshal([]byte(tt.json), &got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decoded %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnmarshalResultMatchesEncodingJSON(t *testing.T) {
+	// plain decodes the known fields the way encoding/json does by itself.
+	type plain RunwareSuccessResponseBody
+	inputs := []string{
+		`{}`,
+		` { "imageUUID" : "a\"b\\" , "taskUUID":"\u00e9t\u00e9" } `,
+		`{"ImageUUID":"upper","TASKTYPE":"imageInference","nsfwcontent":true}`,
+		`{"imageUUID":null,"cost":null,"width":null}`,
+		`{"imageUUID":"café ☕","cost":1e-3,"width":1024,"height":0,"cached":false}`,
+		`{"extra":{"nested":["}",{"a":"\"]"}],"b":[[]]},"imageUUID":"after"}`,
+		`{"imageUUID":"first","imageUUID":"second"}`,
+	}
+	for _, input := range inputs {
+		var want plain
+		if err := json.Unmarshal([]byte(input), &want); err != nil {
+			t.Fatalf("%s: %v", input, err)
+		}
+		var got RunwareSuccessResponseBody
+		if err := json.Unmarshal([]byte(input), &got); err != nil {
+			t.Fatalf("%s: %v", input, err)
+		}
+		got.Extra = nil
+		if !reflect.DeepEqual(plain(got), want) {
+			t.Errorf("%s:\n got %+v\nwant %+v", input, got, want)
+		}
+	}
+}
+
+func TestUnmarshalResultErrors(t *testing.T) {
+	for _, input := range []string{`[]`, `"result"`, `{"imageUUID":"a"`, `{"imageUUID" "a"}`, `{"width":"wide"}`, `{"width":70000}`, `{"seed":1.5}`} {
+		var r RunwareSuccessResponseBody
+		if err := r.UnmarshalJSON([]byte(input)); err == nil {
+			t.Errorf("UnmarshalJSON(%s) succeeded", input)
+		}
+	}
+}
+
+// benchmarkResults holds a typical small result and one carrying a 1MB
+// base64 image, the case where parsing the input twice would cost most.
+var benchmarkResults = map[string][]byte{
+	"small":   []byte(`{"taskType":"imageInference","taskUUID":"0f0a3e5e-8f1c-4b8e-9d7e-2f1c3a4b5c6d","imageUUID":"5d6c7b8a-1e2f-4a3b-8c9d-0e1f2a3b4c5d","imageURL":"https://im.runware.ai/image/ws/0.5/ii/5d6c7b8a.jpg","seed":1234567890,"cost":0.0013,"NSFWContent":false}`),
+	"base64":  []byte(`{"taskType":"imageInference","taskUUID":"0f0a3e5e-8f1c-4b8e-9d7e-2f1c3a4b5c6d","imageUUID":"5d6c7b8a","imageBase64Data":"` + strings.Repeat("QUJD", 256<<10) + `","seed":42,"cost":0.0013}`),
+	"unknown": []byte(`{"taskType":"imageInference","imageUUID":"5d6c7b8a","seed":42,"status":"success","timings":{"queue":12,"inference":840},"provider":"runware"}`),
+}
+
+func BenchmarkUnmarshalResult(b *testing.B) {
+	// plain has the same fields without the custom UnmarshalJSON, as the
+	// baseline encoding/json would give.
+	type plain RunwareSuccessResponseBody
+	for _, name := range []string{"small", "base64", "unknown"} {
+		data := benchmarkResults[name]
+		b.Run(name+"/plain", func(b *testing.B) {
+			b.SetBytes(int64(len(data)))
+			b.ReportAllocs()
+			for b.Loop() {
+				var r struct {
+					plain
+					Seed any `json:"seed"`
+				}
+				if err := json.Unmarshal(data, &r); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run(name+"/extra", func(b *testing.B) {
+			b.SetBytes(int64(len(data)))
+			b.ReportAllocs()
+			for b.Loop() {
+				var r RunwareSuccessResponseBody
+				if err := json.Unmarshal(data, &r); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
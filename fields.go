@@ -0,0 +1,68 @@
+package runware
+
+import (
+	"encoding/json"
+	"slices"
+)
+
+// taskWithExtra encodes a task with its Raw fields added to its own, then the
+// fields set by WithExtraFields merged over both.
+type taskWithExtra struct {
+	task  RunwareOptions
+	extra map[string]any
+}
+
+func (t taskWithExtra) MarshalJSON() ([]byte, error) {
+	encoded, err := json.Marshal(t.task)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(encoded, &fields); err != nil {
+		return nil, err
+	}
+	for key, value := range t.task.Raw {
+		if _, set := fields[key]; set || value == nil {
+			continue
+		}
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		fields[key] = raw
+	}
+	for key, value := range t.extra {
+		if value == nil {
+			delete(fields, key)
+			continue
+		}
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		fields[key] = raw
+	}
+	return json.Marshal(fields)
+}
+
+// withExtraFields returns payload ready to be encoded, with the Raw fields of
+// each task and the fields set by WithExtraFields merged in.
+func (g *generateImagesV1Impl) withExtraFields(payload []RunwareOptions) any {
+	if len(g.extraFields) == 0 && !slices.ContainsFunc(payload, func(task RunwareOptions) bool { return len(task.Raw) > 0 }) {
+		return payload
+	}
+	tasks := make([]any, len(payload))
+	for i, task := range payload {
+		tasks[i] = g.encodedTask(task)
+	}
+	return tasks
+}
+
+// encodedTask returns task ready to be encoded on its own, with its Raw fields
+// and the fields set by WithExtraFields merged in when there are any.
+func (g *generateImagesV1Impl) encodedTask(task RunwareOptions) any {
+	if len(g.extraFields) == 0 && len(task.Raw) == 0 {
+		return task
+	}
+	return taskWithExtra{task: task, extra: g.extraFields}
+}
@@ -5,3 +5,7 @@ go 1.24.2
 require github.com/google/uuid v1.6.0
 
 require github.com/ableinc/go-env v0.1.4
+
+require gopkg.in/yaml.v3 v3.0.1
+
+require golang.org/x/image v0.24.0
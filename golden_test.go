@@ -0,0 +1,115 @@
+package runware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+func ptr[T any](v T) *T { return &v }
+
+// goldenPayloads lists, for each golden file, a task given to Config and the
+// same task built as a RunwareOptions, which must encode identically.
+var goldenPayloads = []struct {
+	name    string
+	config  map[string]any
+	options RunwareOptions
+}{
+	{
+		name:    "unset",
+		config:  map[string]any{"taskUUID": "00000000-0000-0000-0000-000000000001", "taskType": ImageInference, "prompt": "a lighthouse at dusk", "model": "runware:100@1"},
+		options: RunwareOptions{TaskUUID: "00000000-0000-0000-0000-000000000001", TaskType: ImageInference, Prompt: "a lighthouse at dusk", Model: "runware:100@1"},
+	},
+	{
+		name: "zero",
+		config: map[string]any{
+			"taskUUID": "00000000-0000-0000-0000-000000000002", "taskType": ImageInference, "prompt": "a lighthouse at dusk", "model": "runware:100@1",
+			"width": 0, "height": 0, "results": 0, "steps": 0, "outputQuality": 0, "checkNSFW": false, "includeCost": false,
+			"lossless": false, "vaeTiling": false, "CFGScale": 0, "clipSkip": 0, "seed": 0, "strength": 0.0, "negativePrompt": "",
+		},
+		options: RunwareOptions{
+			TaskUUID: "00000000-0000-0000-0000-000000000002", TaskType: ImageInference, Prompt: "a lighthouse at dusk", Model: "runware:100@1",
+			CheckNSFW: ptr(false), IncludeCost: ptr(false), CFGScale: ptr(0.0), ClipSkip: ptr(0), Seed: ptr(int64(0)), Strength: ptr(0.0),
+		},
+	},
+	{
+		name: "explicit",
+		config: map[string]any{
+			"taskUUID": "00000000-0000-0000-0000-000000000003", "taskType": ImageInference, "prompt": "a lighthouse at dusk", "model": "runware:100@1",
+			"negativePrompt": "fog", "promptWeighting": Compel, "uploadEndpoint": "https://example.com/upload",
+			"outputType": Base64Data, "outputFormat": WEBP, "lossless": true, "width": 1024, "height": 768, "results": 2,
+			"checkNSFW": true, "includeCost": true, "seedImage": "c2VlZA==", "maskImage": "bWFzaw==", "maskMargin": 32,
+			"style": "cinematic", "strength": 0.7, "steps": 30, "CFGScale": 7.5, "clipSkip": 2, "seed": 9007199254740993,
+			"vaeTiling": true, "raw": map[string]any{"acceleration": "high"},
+		},
+		options: RunwareOptions{
+			TaskUUID: "00000000-0000-0000-0000-000000000003", TaskType: ImageInference, Prompt: "a lighthouse at dusk", Model: "runware:100@1",
+			NegativePrompt: "fog", PromptWeighting: Compel, UploadEndpoint: "https://example.com/upload",
+			OutputType: Base64Data, OutputFormat: WEBP, Lossless: true, Width: 1024, Height: 768, NumberOfResults: 2,
+			CheckNSFW: ptr(true), IncludeCost: ptr(true), SeedImage: "c2VlZA==", MaskImage: "bWFzaw==", MaskMargin: ptr(32),
+			Style: "cinematic", Strength: ptr(0.7), Steps: 30, CFGScale: ptr(7.5), ClipSkip: ptr(2), Seed: ptr(int64(9007199254740993)),
+			VAETiling: true, Raw: map[string]any{"acceleration": "high"},
+		},
+	},
+}
+
+// encodedBody returns the indented request body buildClient produces for the
+// client's tasks.
+func encodedBody(t *testing.T, g *generateImagesV1Impl) []byte {
+	t.Helper()
+	_, req, err := buildClient(context.Background(), g, "https://api.runware.ai/v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, body, "", "  "); err != nil {
+		t.Fatal(err)
+	}
+	indented.WriteByte('\n')
+	return indented.Bytes()
+}
+
+func TestPayloadGolden(t *testing.T) {
+	for _, tt := range goldenPayloads {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join("testdata", "payload", tt.name+".json")
+			fromConfig := NewGenerateImagesV1(testAPIKey).Config([]map[string]any{tt.config}).(*generateImagesV1Impl)
+			if fromConfig.configErr != nil {
+				t.Fatal(fromConfig.configErr)
+			}
+			got := encodedBody(t, fromConfig)
+			if *update {
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, got, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("Config payload differs from %s:\n%s", path, got)
+			}
+
+			fromStruct := NewGenerateImagesV1(testAPIKey).(*generateImagesV1Impl)
+			fromStruct.options = []RunwareOptions{tt.options}
+			if got := encodedBody(t, fromStruct); !bytes.Equal(got, want) {
+				t.Errorf("RunwareOptions payload differs from %s:\n%s", path, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package runware
+
+import (
+	"context"
+	"time"
+)
+
+type attemptOutcome struct {
+	data *[]RunwareSuccessResponseBody
+	err  error
+}
+
+// sendHedged sends one attempt. When hedging is enabled and every task carries
+// an explicit taskUUID, a second identical request is issued if the first has
+// not completed within the hedge delay. The first successful response wins and
+// the other request is cancelled; if both land, their results are merged.
+func sendHedged(ctx context.Context, g *generateImagesV1Impl, urls []string) (*[]RunwareSuccessResponseBody, error) {
+	if g.hedgeDelay <= 0 || !g.explicitUUIDs {
+		return sendGuarded(ctx, g, urls)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	outcomes := make(chan attemptOutcome, 2)
+	launch := func() {
+		go func() {
+			data, err := sendGuarded(ctx, g, urls)
+			outcomes <- attemptOutcome{data, err}
+		}()
+	}
+	launch()
+	inflight := 1
+	hedge := time.NewTimer(g.hedgeDelay)
+	defer hedge.Stop()
+	for {
+		select {
+		case <-hedge.C:
+			launch()
+			inflight++
+		case outcome := <-outcomes:
+			inflight--
+			if outcome.err == nil {
+				select {
+				case other := <-outcomes:
+					if other.err == nil {
+						merged := mergeResults(*outcome.data, *other.data)
+						return &merged, nil
+					}
+				default:
+				}
+				return outcome.data, nil
+			}
+			if inflight == 0 {
+				// Nothing else is in flight: either the hedge never fired or
+				// both requests failed.
+				return outcome.data, outcome.err
+			}
+		}
+	}
+}
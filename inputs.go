@@ -0,0 +1,76 @@
+package runware
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"strings"
+)
+
+// SeedImageReader reads r to the end and sets it, base64-encoded, as the seed
+// image of every configured task. The image is encoded while it is read, so an
+// upload can be passed straight from a request body. A read error is reported
+// by GenerateV1 before anything is sent. r is not closed. It must be called
+// after Config, which replaces the tasks; called before, r is not read and the
+// next GenerateV1 reports the mistake, up to the following Config.
+func (g *generateImagesV1Impl) SeedImageReader(r io.Reader) GenerateImagesV1 {
+	if len(g.options) == 0 {
+		g.earlyConfigErr = errors.Join(g.earlyConfigErr, errors.New("SeedImageReader called before Config: there is no task to set the seed image of"))
+		return g
+	}
+	var encoded strings.Builder
+	encoder := base64.NewEncoder(base64.StdEncoding, &encoded)
+	_, err := io.Copy(encoder, r)
+	if err == nil {
+		err = encoder.Close()
+	}
+	if err == nil && encoded.Len() == 0 {
+		err = fmt.Errorf("image is empty")
+	}
+	for i := range g.options {
+		if err != nil {
+			g.addConfigErr(i, "seedImage", fmt.Errorf("failed to read image: %w", err))
+			continue
+		}
+		g.options[i].SeedImage = encoded.String()
+	}
+	return g
+}
+
+// checkInputImages reports inline input images that do not decode or are not
+// in a supported format (PNG, JPEG or WEBP), when WithValidateInputImages is
+// enabled. Only the image header is parsed.
+func (g *generateImagesV1Impl) checkInputImages(i int, option RunwareOptions) []error {
+	if !g.validateInputImages {
+		return nil
+	}
+	images := []struct{ name, value string }{
+		{"seedImage", option.SeedImage},
+		{"maskImage", option.MaskImage},
+		{"inputImage", option.InputImage},
+	}
+	for j, value := range option.InputImages {
+		images = append(images, struct{ name, value string }{fmt.Sprintf("inputImages[%d]", j), value})
+	}
+	var errs []error
+	for _, img := range images {
+		data, inline, err := inlineImage(img.value)
+		if !inline {
+			continue
+		}
+		if err == nil {
+			_, _, err = image.DecodeConfig(bytes.NewReader(data))
+		}
+		// Built with runware_nowebp, only the WEBP signature can be checked.
+		if missingWEBP(data, err) {
+			err = nil
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("task %d: %s is not a valid PNG, JPEG or WEBP image: %w", i, img.name, err))
+		}
+	}
+	return errs
+}
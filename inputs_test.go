@@ -0,0 +1,92 @@
+package runware
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func TestValidateInputImages(t *testing.T) {
+	valid := base64.StdEncoding.EncodeToString(testPNG(t))
+	tests := []struct {
+		name    string
+		values  map[string]any
+		enabled bool
+		wantErr string
+	}{
+		{"valid base64", map[string]any{"seedImage": valid, "strength": 0.5}, true, ""},
+		{"valid unpadded base64", map[string]any{"seedImage": base64.RawStdEncoding.EncodeToString(testPNG(t)), "strength": 0.5}, true, ""},
+		{"valid URL-safe base64", map[string]any{"seedImage": base64.RawURLEncoding.EncodeToString(testPNG(t)), "strength": 0.5}, true, ""},
+		{"valid data URI", map[string]any{"seedImage": "data:image/png;base64," + valid, "strength": 0.5}, true, ""},
+		{"image by UUID", map[string]any{"seedImage": "6b6c7c1e-3c2a-4d4f-9e0e-7f1d1e2f3a4b", "strength": 0.5}, true, ""},
+		{"image by URL", map[string]any{"seedImage": "https://example.com/seed.png", "strength": 0.5}, true, ""},
+		{"corrupt base64", map[string]any{"seedImage": "not*base64!", "strength": 0.5}, true, "seedImage is not a valid"},
+		{"base64 of a non-image", map[string]any{"seedImage": base64.StdEncoding.EncodeToString([]byte("hello")), "strength": 0.5}, true, "seedImage is not a valid"},
+		{"corrupt inputImages entry", map[string]any{"inputImages": []string{valid, "AAAA"}}, true, "inputImages[1] is not a valid"},
+		{"corrupt but disabled", map[string]any{"seedImage": "not*base64!", "strength": 0.5}, false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newFakeAPI(t, echoResults)
+			_, err := api.client(WithValidateInputImages(tt.enabled)).Config([]map[string]any{testTask(tt.values)}).GenerateV1()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("GenerateV1() = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("GenerateV1() = %v, want an error containing %q", err, tt.wantErr)
+			}
+			if api.requests() != 0 {
+				t.Error("a corrupt image was sent")
+			}
+		})
+	}
+}
+
+func TestSeedImageReaderBeforeConfig(t *testing.T) {
+	api := newFakeAPI(t, echoResults)
+	client := api.client().SeedImageReader(strings.NewReader("image")).Config([]map[string]any{testTask(nil)})
+	_, err := client.GenerateV1()
+	if err == nil || !strings.Contains(err.Error(), "SeedImageReader called before Config") {
+		t.Fatalf("GenerateV1() = %v, want the misplaced SeedImageReader reported", err)
+	}
+	if api.requests() != 0 {
+		t.Error("the task was sent without its seed image")
+	}
+}
+
+func TestSeedImageReaderAfterConfig(t *testing.T) {
+	api := newFakeAPI(t, echoResults)
+	png := testPNG(t)
+	client := api.client().Config([]map[string]any{testTask(map[string]any{"strength": 0.5})}).SeedImageReader(bytes.NewReader(png))
+	if _, err := client.GenerateV1(); err != nil {
+		t.Fatal(err)
+	}
+	if got := api.lastTasks(t)[0]["seedImage"]; got != base64.StdEncoding.EncodeToString(png) {
+		t.Errorf("seedImage = %.20v..., want the encoded image", got)
+	}
+}
+
+func TestSeedImageReaderMisuseDoesNotOutliveReconfiguring(t *testing.T) {
+	api := newFakeAPI(t, echoResults)
+	client := api.client()
+	client.SeedImageReader(strings.NewReader("image"))
+	if _, err := client.GenerateV1(); err == nil || !strings.Contains(err.Error(), "SeedImageReader called before Config") {
+		t.Fatalf("GenerateV1() = %v, want the misplaced SeedImageReader reported", err)
+	}
+	png := testPNG(t)
+	client.Config([]map[string]any{testTask(map[string]any{"strength": 0.5})}).SeedImageReader(bytes.NewReader(png))
+	if _, err := client.GenerateV1(); err == nil || !strings.Contains(err.Error(), "SeedImageReader called before Config") {
+		t.Fatalf("GenerateV1() = %v, want the misuse reported by the Config that followed it", err)
+	}
+	client.Config([]map[string]any{testTask(map[string]any{"strength": 0.5})}).SeedImageReader(bytes.NewReader(png))
+	if _, err := client.GenerateV1(); err != nil {
+		t.Fatalf("GenerateV1() after reconfiguring = %v", err)
+	}
+	if got := api.lastTasks(t)[0]["seedImage"]; got != base64.StdEncoding.EncodeToString(png) {
+		t.Errorf("seedImage = %.20v..., want the encoded image", got)
+	}
+}
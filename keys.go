@@ -0,0 +1,46 @@
+package runware
+
+import (
+	"context"
+	"errors"
+	"log"
+)
+
+// isKeyFailure reports whether err is tied to the API key or its account
+// (quota, credits, authentication) rather than to the request, so that the
+// same request may succeed under another key.
+func isKeyFailure(err error) bool {
+	var rateErr *RateLimitedError
+	return errors.As(err, &rateErr) ||
+		errors.Is(err, ErrInsufficientCredits) ||
+		errors.Is(err, ErrInvalidAPIKey) ||
+		errors.Is(err, ErrUnauthorized) ||
+		errors.Is(err, ErrForbidden)
+}
+
+// sendWithKeys sends one attempt under the first API key set by WithAPIKeys,
+// moving on to the next key when the failure is tied to the key. Validation
+// errors and server failures are returned as they are. Keys are identified by
+// their index in logs and in ResponseMeta.KeyIndex, never by value.
+func sendWithKeys(ctx context.Context, g *generateImagesV1Impl, urls []string) (*[]RunwareSuccessResponseBody, error) {
+	if len(g.apiKeys) == 0 {
+		return sendFailover(ctx, g, urls)
+	}
+	var (
+		data *[]RunwareSuccessResponseBody
+		err  error
+	)
+	for i, key := range g.apiKeys {
+		keyed := *g
+		keyed.apiKey = key
+		keyed.keyIndex = i
+		data, err = sendFailover(ctx, &keyed, urls)
+		if err == nil || ctx.Err() != nil || !isKeyFailure(err) {
+			return data, err
+		}
+		if i+1 < len(g.apiKeys) {
+			log.Printf("API key %d failed (%v), failing over to key %d", i, err, i+1)
+		}
+	}
+	return data, err
+}
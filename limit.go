@@ -0,0 +1,57 @@
+package runware
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// DefaultMaxResponseBytes caps response bodies unless WithMaxResponseBytes
+// says otherwise. At 512MB it leaves room for large batches of
+// base64-encoded images; raise it if your batches are bigger still.
+const DefaultMaxResponseBytes int64 = 512 << 20
+
+// ErrResponseTooLarge matches, through errors.Is, the *ResponseTooLargeError
+// returned when a response body exceeds the limit set by
+// WithMaxResponseBytes.
+var ErrResponseTooLarge = errors.New("response body exceeds the maximum allowed size")
+
+// ResponseTooLargeError reports a response body larger than the configured
+// limit. Read is how many bytes had been read when the limit was hit.
+type ResponseTooLargeError struct {
+	Limit int64
+	Read  int64
+}
+
+func (e *ResponseTooLargeError) Error() string {
+	return fmt.Sprintf("response body exceeds the maximum allowed size of %d bytes (read %d bytes)", e.Limit, e.Read)
+}
+
+func (e *ResponseTooLargeError) Is(target error) bool { return target == ErrResponseTooLarge }
+
+// maxBytesReader reads at most limit bytes from r and fails with a
+// *ResponseTooLargeError if r holds more.
+type maxBytesReader struct {
+	r     io.Reader
+	limit int64
+	read  int64
+}
+
+func (m *maxBytesReader) Read(p []byte) (int, error) {
+	remaining := m.limit - m.read
+	if remaining <= 0 {
+		// Only fail if there really is more data past the limit.
+		var probe [1]byte
+		k, err := m.r.Read(probe[:])
+		if k == 0 {
+			return 0, err
+		}
+		return 0, &ResponseTooLargeError{Limit: m.limit, Read: m.read + int64(k)}
+	}
+	if int64(len(p)) > remaining {
+		p = p[:remaining]
+	}
+	k, err := m.r.Read(p)
+	m.read += int64(k)
+	return k, err
+}
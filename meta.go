@@ -0,0 +1,48 @@
+package runware
+
+import (
+	"net/http"
+	"time"
+)
+
+// ResponseMeta is what the client keeps from a response's status line and
+// headers, e.g. to quote the request ID in a support ticket.
+type ResponseMeta struct {
+	StatusCode int
+	// RequestID is the X-Request-Id header, if the API sent one.
+	RequestID string
+	// Date is the response's Date header, or zero when missing.
+	Date time.Time
+	// RateLimit is nil when the response had no rate-limit headers.
+	RateLimit *RateLimitInfo
+	// KeyIndex is the index, in the list given to WithAPIKeys, of the key
+	// the request was sent with. It is zero without WithAPIKeys.
+	KeyIndex int
+	// Endpoint is the URL the request was sent to, which shows the endpoint
+	// chosen when WithEndpoints lists several.
+	Endpoint string
+}
+
+func newResponseMeta(resp *http.Response) *ResponseMeta {
+	meta := &ResponseMeta{
+		StatusCode: resp.StatusCode,
+		RequestID:  resp.Header.Get("X-Request-Id"),
+		RateLimit:  parseRateLimit(resp.Header),
+	}
+	if date, err := http.ParseTime(resp.Header.Get("Date")); err == nil {
+		meta.Date = date
+	}
+	return meta
+}
+
+func (g *generateImagesV1Impl) LastRawResponse() []byte {
+	g.state.mu.Lock()
+	defer g.state.mu.Unlock()
+	return g.state.lastRaw
+}
+
+func (g *generateImagesV1Impl) LastResponseMeta() *ResponseMeta {
+	g.state.mu.Lock()
+	defer g.state.mu.Unlock()
+	return g.state.lastMeta
+}
@@ -0,0 +1,71 @@
+package runware
+
+import (
+	"sync"
+	"time"
+)
+
+// Metrics receives usage measurements from every request the client sends.
+// Implementations must be safe for concurrent use; adapt it to Prometheus or
+// any other backend by forwarding the calls to counters and histograms.
+type Metrics interface {
+	IncRequests()
+	IncFailures()
+	ObserveLatency(d time.Duration)
+	AddCost(cost float64)
+}
+
+type noopMetrics struct{}
+
+func (noopMetrics) IncRequests()                 {}
+func (noopMetrics) IncFailures()                 {}
+func (noopMetrics) ObserveLatency(time.Duration) {}
+func (noopMetrics) AddCost(float64)              {}
+
+// MetricsSnapshot is a point-in-time copy of InMemoryMetrics.
+type MetricsSnapshot struct {
+	Requests     int
+	Failures     int
+	TotalLatency time.Duration
+	MaxLatency   time.Duration
+	Cost         float64
+}
+
+// InMemoryMetrics is a Metrics implementation that keeps running totals in
+// memory, mostly useful for tests and simple dashboards.
+type InMemoryMetrics struct {
+	mu       sync.Mutex
+	snapshot MetricsSnapshot
+}
+
+func (m *InMemoryMetrics) IncRequests() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.snapshot.Requests++
+}
+
+func (m *InMemoryMetrics) IncFailures() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.snapshot.Failures++
+}
+
+func (m *InMemoryMetrics) ObserveLatency(d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.snapshot.TotalLatency += d
+	m.snapshot.MaxLatency = max(m.snapshot.MaxLatency, d)
+}
+
+func (m *InMemoryMetrics) AddCost(cost float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.snapshot.Cost += cost
+}
+
+// Snapshot returns the totals recorded so far.
+func (m *InMemoryMetrics) Snapshot() MetricsSnapshot {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.snapshot
+}
@@ -0,0 +1,5 @@
+//go:build runware_nowebp
+
+package runware
+
+const webpSupported = false
@@ -0,0 +1,34 @@
+//go:build runware_nowebp
+
+package runware
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"image"
+	"strings"
+	"testing"
+)
+
+func TestWEBPLeftOut(t *testing.T) {
+	webp := base64.StdEncoding.EncodeToString(fixture(t, "fixture.webp"))
+	result := RunwareSuccessResponseBody{ImageUUID: "w", ImageBase64Data: webp}
+	_, _, decodeErr := result.DecodeImage()
+	_, pngErr := result.AsPNG(context.Background(), nil)
+	_, _, resizeErr := resizeInputImage(webp)
+	for name, err := range map[string]error{"DecodeImage": decodeErr, "AsPNG": pngErr, "resizeInputImage": resizeErr} {
+		if !errors.Is(err, image.ErrFormat) || !strings.Contains(err.Error(), "runware_nowebp") {
+			t.Errorf("%s = %v, want image.ErrFormat naming the build tag", name, err)
+		}
+	}
+}
+
+func TestValidateInputImagesAcceptsWEBPSignature(t *testing.T) {
+	webp := base64.StdEncoding.EncodeToString(fixture(t, "fixture.webp"))
+	api := newFakeAPI(t, echoResults)
+	task := testTask(map[string]any{"seedImage": webp, "strength": 0.5})
+	if _, err := api.client(WithValidateInputImages(true)).Config([]map[string]any{task}).GenerateV1(); err != nil {
+		t.Errorf("GenerateV1() = %v", err)
+	}
+}
@@ -0,0 +1,421 @@
+package runware
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Option configures optional client behaviour in NewGenerateImagesV1.
+type Option func(*generateImagesV1Impl)
+
+// WithRetries retries a failed GenerateV1 call up to maxRetries times when the
+// failure is transient (timeouts, connection failures, 5xx and 429
+// responses). The wait between attempts starts at backoff and doubles after
+// each attempt; a 429 response waits for its Retry-After duration instead.
+func WithRetries(maxRetries int, backoff time.Duration) Option {
+	return func(g *generateImagesV1Impl) {
+		g.maxRetries = maxRetries
+		g.retryBackoff = backoff
+	}
+}
+
+// WithEndpoints sets the base URLs requests are sent to, in order of
+// preference, replacing DefaultEndpoint. When an endpoint cannot be reached or
+// answers with a 5xx the same attempt moves on to the next one immediately, so
+// a degraded region does not use up retries and backoff; only when every
+// endpoint has failed does the attempt count as failed. 4xx responses are
+// returned without failover. A failed endpoint is skipped by later attempts
+// and calls for the cooldown set by WithEndpointCooldown, unless every
+// endpoint is cooling down. LastResponseMeta reports the endpoint used.
+// Invalid URLs are reported by GenerateV1.
+func WithEndpoints(endpoints []string) Option {
+	return func(g *generateImagesV1Impl) {
+		for _, endpoint := range endpoints {
+			if err := checkEndpoint(endpoint); err != nil {
+				g.addOptionErr(fmt.Errorf("invalid endpoint: %w", err))
+			}
+		}
+		g.endpoints = append([]string(nil), endpoints...)
+	}
+}
+
+// WithEndpointCooldown sets how long an endpoint that failed is skipped for,
+// replacing DefaultEndpointCooldown. Zero turns the health memory off.
+func WithEndpointCooldown(d time.Duration) Option {
+	return func(g *generateImagesV1Impl) {
+		if d < 0 {
+			g.addOptionErr(fmt.Errorf("invalid endpoint cooldown %v: must not be negative", d))
+			return
+		}
+		g.endpointCooldown = d
+	}
+}
+
+// WithAPIVersion sets the version path segment, such as "v2", appended to
+// every endpoint in place of DefaultAPIVersion. It lets a client opt in to a
+// new or beta API version before the SDK defaults to it. A version that is not
+// a single path segment is reported by GenerateV1.
+func WithAPIVersion(version string) Option {
+	return func(g *generateImagesV1Impl) {
+		if !apiVersionPattern.MatchString(version) {
+			g.addOptionErr(fmt.Errorf("invalid API version %q: must be a single path segment such as %q", version, DefaultAPIVersion))
+			return
+		}
+		g.apiVersion = version
+	}
+}
+
+// WithCostBudget stops the client from sending requests once the total cost
+// reported for its results reaches limit; calls then fail with a
+// *BudgetExceededError. The budget is checked before each call, so the call
+// that crosses it still completes, and concurrent calls may overshoot it
+// together. Cost is only reported when tasks set includeCost, so without it
+// the budget is never enforced. Copies of the client made by Variations share
+// its total.
+func WithCostBudget(limit float64) Option {
+	return func(g *generateImagesV1Impl) {
+		g.costBudget = limit
+	}
+}
+
+// WithStrictDecoding logs a warning for every result field in a response
+// that RunwareSuccessResponseBody does not declare, so new API fields get
+// noticed. Decoding stays tolerant either way: the response is still
+// returned, with the new fields available in Extra.
+func WithStrictDecoding(enabled bool) Option {
+	return func(g *generateImagesV1Impl) {
+		g.strictDecoding = enabled
+	}
+}
+
+// WithStepClamping lowers a step count above the model's maximum to that
+// maximum, logging a warning, instead of rejecting the task. Distilled models
+// such as FLUX.1 [schnell] only need a handful of steps, so larger counts
+// waste credits.
+func WithStepClamping(enabled bool) Option {
+	return func(g *generateImagesV1Impl) {
+		g.clampSteps = enabled
+	}
+}
+
+// WithCaptureRawResponse keeps the body of the most recent response, as far
+// as it was read, for LastRawResponse, to see exactly what the server sent
+// when decoding goes wrong. The body is copied while it is decoded, so this
+// doubles the memory a response takes, including for GenerateToSink.
+func WithCaptureRawResponse(enabled bool) Option {
+	return func(g *generateImagesV1Impl) {
+		g.captureRaw = enabled
+	}
+}
+
+// WithAllowUnknownTaskTypes lets tasks whose taskType is not in the
+// package's registry through Validate and GenerateV1, for using task types the
+// API has added before this package supports them. Such tasks are sent with
+// the same fields as any other.
+func WithAllowUnknownTaskTypes(allow bool) Option {
+	return func(g *generateImagesV1Impl) {
+		g.allowUnknownTasks = allow
+	}
+}
+
+// WithDefaultOutput sets the output type and format used by tasks that do not
+// set their own; either may be left empty to keep the API's default. Values
+// given in Config or Override always win.
+func WithDefaultOutput(outputType OutputType, outputFormat OutputFormat) Option {
+	return func(g *generateImagesV1Impl) {
+		g.defaultOutputType = outputType
+		g.defaultOutputFormat = outputFormat
+	}
+}
+
+// WithAPIKeys sets the API keys to use in priority order, replacing the key
+// given to NewGenerateImagesV1. Each request is sent with the first key; when
+// it fails for a reason tied to the key or its account (insufficient credits,
+// rate limiting, a 401 or 403) the same request is sent again right away with
+// the next key. Other errors, such as invalid parameters, are returned
+// without trying other keys. ResponseMeta.KeyIndex records which key served a
+// request.
+func WithAPIKeys(keys []string) Option {
+	return func(g *generateImagesV1Impl) {
+		g.apiKeys = append([]string(nil), keys...)
+		if len(keys) > 0 {
+			g.apiKey = keys[0]
+		}
+	}
+}
+
+// WithValidateInputImages checks, before sending, that seed, mask and input
+// images given inline as base64 or data URIs decode to a PNG, JPEG or WEBP
+// image, so a corrupt image fails locally with the task and field named
+// instead of after a round trip. Images referenced by UUID or URL are not
+// checked, nor, when built with -tags runware_nowebp, is more than the
+// signature of a WEBP image. Decoding large images has a cost, so the check is
+// off by default.
+func WithValidateInputImages(enabled bool) Option {
+	return func(g *generateImagesV1Impl) {
+		g.validateInputImages = enabled
+	}
+}
+
+// WithAuditLog writes a JSON line to w for every task of every request sent,
+// successful or not, with the time, task UUID, model, a SHA-256 hash of the
+// prompt, dimensions, cost, status and latency; see AuditRecord. Prompts,
+// images and the API key are never written. A failed write is logged and does
+// not fail the call.
+func WithAuditLog(w io.Writer) Option {
+	return func(g *generateImagesV1Impl) {
+		if w == nil {
+			g.auditLog = nil
+			return
+		}
+		g.auditLog = &auditLog{w: w}
+	}
+}
+
+// WithAuthHeader sends the API key in the header name, formatted with format,
+// instead of "Authorization: Bearer <key>". Use it behind gateways that
+// expect another scheme, e.g. WithAuthHeader("X-API-Key", "%s"). format must
+// contain exactly one %s; an invalid header or format is reported by
+// GenerateV1.
+func WithAuthHeader(name, format string) Option {
+	return func(g *generateImagesV1Impl) {
+		if name == "" || strings.ContainsAny(name, " \t\r\n:") {
+			g.addOptionErr(fmt.Errorf("invalid auth header name %q", name))
+			return
+		}
+		if strings.Count(format, "%") != 1 || !strings.Contains(format, "%s") {
+			g.addOptionErr(fmt.Errorf("invalid auth header format %q: must contain exactly one %%s", format))
+			return
+		}
+		g.authHeader = name
+		g.authFormat = format
+	}
+}
+
+// WithQueue sets how many tasks Enqueue buffers before returning ErrQueueFull,
+// replacing DefaultQueueCapacity, and how many Flush sends per request,
+// replacing DefaultBatchSize. Values below one are reported by GenerateV1.
+func WithQueue(capacity, batchSize int) Option {
+	return func(g *generateImagesV1Impl) {
+		if capacity < 1 || batchSize < 1 {
+			g.addOptionErr(fmt.Errorf("invalid queue: capacity and batch size must be at least 1, got %d and %d", capacity, batchSize))
+			return
+		}
+		g.queueCapacity = capacity
+		g.batchSize = batchSize
+	}
+}
+
+// WithExtraFields merges fields into every task sent, after the typed fields,
+// so that parameters the API has added or renamed can be used before this
+// package supports them. A field overrides a typed field of the same JSON
+// name, and a nil value removes it, e.g. {"cfgScale": 7.5, "CFGScale": nil}.
+//
+// The fields are sent as given, bypassing every check made before sending, so
+// a mistake is only reported by the API; they may also conflict with typed
+// fields or change meaning between API versions. Values that cannot be
+// encoded as JSON are reported by GenerateV1.
+func WithExtraFields(fields map[string]any) Option {
+	return func(g *generateImagesV1Impl) {
+		for key, value := range fields {
+			if _, err := json.Marshal(value); err != nil {
+				g.addOptionErr(fmt.Errorf("invalid extra field %q: %w", key, err))
+			}
+		}
+		g.extraFields = maps.Clone(fields)
+	}
+}
+
+// WithAutoResize resizes inline seed and mask images (base64 or data URIs in
+// PNG, JPEG or WEBP) to the nearest valid dimensions before they are sent, so
+// image-to-image tasks do not fail on sizes that are not multiples of 64.
+// Resized WEBP images are sent as PNG; built with -tags runware_nowebp, a WEBP
+// image fails the call instead. Tasks without a width and height take
+// the resized seed image's size. What was changed is reported by
+// LastAdjustments.
+func WithAutoResize(enabled bool) Option {
+	return func(g *generateImagesV1Impl) {
+		g.autoResize = enabled
+	}
+}
+
+// WithMaxElapsed bounds the total time a single GenerateV1 call may take across
+// all retry attempts, backoff sleeps and Retry-After waits. When the budget
+// runs out the last error is returned, wrapped with the number of attempts
+// made. A deadline on the caller's context still applies if it is sooner.
+func WithMaxElapsed(d time.Duration) Option {
+	return func(g *generateImagesV1Impl) {
+		g.maxElapsed = d
+	}
+}
+
+// WithCircuitBreaker stops sending requests after threshold consecutive
+// transient failures (timeouts, connection failures and 5xx responses). While
+// open, calls fail fast with ErrCircuitOpen; once cooldown has elapsed a
+// single probe request is allowed through to decide whether to close the
+// breaker again.
+// The breaker state is shared by every goroutine using the client. A
+// threshold below one or a negative cooldown is reported by GenerateV1.
+func WithCircuitBreaker(threshold int, cooldown time.Duration) Option {
+	return func(g *generateImagesV1Impl) {
+		if threshold < 1 || cooldown < 0 {
+			g.addOptionErr(fmt.Errorf("invalid circuit breaker: threshold must be at least 1 and cooldown not negative, got %d and %v", threshold, cooldown))
+			return
+		}
+		g.breaker = &circuitBreaker{threshold: threshold, cooldown: cooldown}
+	}
+}
+
+// WithAspectValidation makes Validate and GenerateV1 reject tasks whose width
+// and height come from different preset families, such as an SD preset width
+// with an HD preset height, or form an aspect ratio beyond MaxAspectRatio.
+// See ValidateAspect.
+func WithAspectValidation(enabled bool) Option {
+	return func(g *generateImagesV1Impl) {
+		g.validateAspect = enabled
+	}
+}
+
+// WithFilenameTemplate sets the file name pattern used by GenerateAndSave.
+// The placeholders {taskUUID}, {imageUUID}, {index} and {ext} are replaced
+// for each result. The default is DefaultFilenameTemplate. A result whose
+// UUIDs contain a path separator or "..", or a name that would land outside
+// the output directory, fails GenerateAndSave.
+func WithFilenameTemplate(template string) Option {
+	return func(g *generateImagesV1Impl) {
+		g.filenameTemplate = template
+	}
+}
+
+// WithHedging sends a second, identical request when the first has not
+// completed within delay, and uses whichever response arrives first. Hedging
+// only applies when every configured task has an explicit taskUUID, so that
+// the duplicate request is idempotent. Each hedge is a separate request as far
+// as the circuit breaker is concerned, and it happens within a single retry
+// attempt, so it does not consume retries.
+func WithHedging(delay time.Duration) Option {
+	return func(g *generateImagesV1Impl) {
+		g.hedgeDelay = delay
+	}
+}
+
+// WithMetrics reports request counts, failures, latency and cost to m. By
+// default measurements are discarded.
+func WithMetrics(m Metrics) Option {
+	return func(g *generateImagesV1Impl) {
+		if m == nil {
+			m = noopMetrics{}
+		}
+		g.metrics = m
+	}
+}
+
+// WithRequestSigner calls sign on every outgoing request once its body and
+// headers are final, e.g. to attach an HMAC required by a gateway. The body
+// can be read without consuming it through req.GetBody. Returning an error
+// aborts the call.
+func WithRequestSigner(sign func(req *http.Request) error) Option {
+	return func(g *generateImagesV1Impl) {
+		g.requestSigner = sign
+	}
+}
+
+// WithStrictConfig makes Config reject taskType, outputType and outputFormat
+// strings that do not name a known constant. By default unknown output types
+// and formats are passed through to the API unchanged, so new API values can
+// be used before this package knows about them; unknown task types are
+// rejected by Validate and GenerateV1 unless WithAllowUnknownTaskTypes is set.
+func WithStrictConfig(strict bool) Option {
+	return func(g *generateImagesV1Impl) {
+		g.strictConfig = strict
+	}
+}
+
+// DefaultStreamThreshold is the amount of input image data above which
+// request bodies are streamed rather than buffered.
+const DefaultStreamThreshold = 4 << 20
+
+// WithStreamingThreshold sets the amount of input image data, in bytes, above
+// which the request body is JSON-encoded while it is being sent instead of
+// being buffered in memory first. A value of zero or less always buffers. The
+// default is DefaultStreamThreshold.
+func WithStreamingThreshold(bytes int) Option {
+	return func(g *generateImagesV1Impl) {
+		g.streamThreshold = bytes
+	}
+}
+
+// WithTransport sends every request through rt instead of the client's
+// default transport, e.g. to record and replay API traffic in tests
+// without a live API key. Only the transport of the client's own http.Client
+// is replaced; everything layered above it, such as retries and the circuit
+// breaker, still applies.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(g *generateImagesV1Impl) {
+		g.httpClient.Transport = rt
+	}
+}
+
+// WithDimensionRounding rounds each width and height to the nearest value the
+// API accepts (a multiple of DimensionMultiple between MinDimension and
+// MaxDimension) instead of rejecting the task.
+func WithDimensionRounding(enabled bool) Option {
+	return func(g *generateImagesV1Impl) {
+		g.roundDimensions = enabled
+	}
+}
+
+// WithPromptTruncation cuts prompts longer than MaxPromptLength down to the
+// limit, logging a warning, instead of rejecting the task.
+func WithPromptTruncation(enabled bool) Option {
+	return func(g *generateImagesV1Impl) {
+		g.truncatePrompts = enabled
+	}
+}
+
+// WithMaxResponseBytes limits how much of a response body is read. Larger
+// responses fail with a *ResponseTooLargeError, matching ErrResponseTooLarge,
+// instead of exhausting memory. The default is DefaultMaxResponseBytes (512MB),
+// which fits large base64 batches; zero or less removes the limit.
+func WithMaxResponseBytes(n int64) Option {
+	return func(g *generateImagesV1Impl) {
+		g.maxResponseBytes = n
+	}
+}
+
+// WithPromptSyntaxValidation checks the emphasis syntax of every prompt and
+// negative prompt before sending, according to the task's PromptWeighting.
+// See ValidatePromptSyntax.
+func WithPromptSyntaxValidation(enabled bool) Option {
+	return func(g *generateImagesV1Impl) {
+		g.validatePromptSyntax = enabled
+	}
+}
+
+// WithTransportConfig tunes the connection pool of the client's transport.
+// All requests made through one client share the pool, so servers running many
+// concurrent generations should raise maxIdleConnsPerHost to roughly their
+// concurrency; otherwise connections beyond the idle limit are closed after
+// each request and must be re-established, TLS handshake included. Higher
+// limits and longer idleTimeouts keep more sockets open in exchange. It
+// replaces any transport set by an earlier WithTransport, and vice versa.
+func WithTransportConfig(maxIdleConns, maxIdleConnsPerHost int, idleTimeout time.Duration) Option {
+	return func(g *generateImagesV1Impl) {
+		g.httpClient.Transport = newTransport(maxIdleConns, maxIdleConnsPerHost, idleTimeout)
+	}
+}
+
+// WithRateLimitObserver calls observe with the rate-limit headers of every
+// response that carries them, so callers can throttle themselves before the
+// API starts answering 429. Responses without the headers are not reported.
+// observe may be called from several goroutines at once.
+func WithRateLimitObserver(observe func(RateLimitInfo)) Option {
+	return func(g *generateImagesV1Impl) {
+		g.rateLimitObserver = observe
+	}
+}
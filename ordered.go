@@ -0,0 +1,36 @@
+package runware
+
+import (
+	"context"
+	"slices"
+)
+
+// GenerateV1Ordered runs GenerateV1 and sorts the results into the order of the
+// configured tasks, so they can be matched to their inputs by position. The
+// results of one task keep the order the API sent them in, and results for
+// task UUIDs that were not configured come last. Partial results returned
+// with an error are sorted too.
+func (g *generateImagesV1Impl) GenerateV1Ordered() (*[]RunwareSuccessResponseBody, error) {
+	data, err := g.GenerateV1Context(context.Background())
+	if data != nil {
+		orderResults(g.options, *data)
+	}
+	return data, err
+}
+
+// orderResults stably sorts results by the position of their task in options.
+func orderResults(options []RunwareOptions, results []RunwareSuccessResponseBody) {
+	position := make(map[string]int, len(options))
+	for i, option := range options {
+		position[option.TaskUUID] = i
+	}
+	rank := func(r RunwareSuccessResponseBody) int {
+		if i, ok := position[r.TaskUUID]; ok {
+			return i
+		}
+		return len(options)
+	}
+	slices.SortStableFunc(results, func(a, b RunwareSuccessResponseBody) int {
+		return rank(a) - rank(b)
+	})
+}
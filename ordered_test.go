@@ -0,0 +1,34 @@
+package runware
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestGenerateV1OrderedSortsShuffledResults(t *testing.T) {
+	api := newFakeAPI(t, respondJSON(http.StatusOK, `{"data":[
+		{"taskUUID":"c","imageUUID":"c1"},
+		{"taskUUID":"stranger","imageUUID":"s1"},
+		{"taskUUID":"a","imageUUID":"a1"},
+		{"taskUUID":"c","imageUUID":"c2"},
+		{"taskUUID":"b","imageUUID":"b1"},
+		{"taskUUID":"a","imageUUID":"a2"}
+	]}`))
+	client := api.client().Config([]map[string]any{
+		testTask(map[string]any{"taskUUID": "a", "results": 2}),
+		testTask(map[string]any{"taskUUID": "b"}),
+		testTask(map[string]any{"taskUUID": "c", "results": 2}),
+	})
+	results, err := client.GenerateV1Ordered()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, r := range *results {
+		got = append(got, r.ImageUUID)
+	}
+	if want := []string{"a1", "a2", "b1", "c1", "c2", "s1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("order = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,69 @@
+package runware
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Output quality bounds accepted by the API for lossy formats.
+const (
+	MinOutputQuality = 20
+	MaxOutputQuality = 99
+)
+
+// compatibleOutputFormats lists, for each known output type, the output
+// formats it can be combined with; "" stands for leaving the format unset.
+// Types and formats missing from the table are passed through unchecked, so
+// values added to the API later keep working.
+var compatibleOutputFormats = map[OutputType][]OutputFormat{
+	Base64Data: {"", PNG, JPG, WEBP},
+	// A data URI has to declare its media type.
+	DataURI: {PNG, JPG, WEBP},
+	URL:     {"", PNG, JPG, WEBP},
+}
+
+// lossyOutputFormats are the formats outputQuality applies to.
+var lossyOutputFormats = []OutputFormat{JPG, WEBP}
+
+// withDefaultOutput fills in the output type and format set by
+// WithDefaultOutput where the task leaves them unset.
+func (g *generateImagesV1Impl) withDefaultOutput(option RunwareOptions) RunwareOptions {
+	if option.OutputType == "" {
+		option.OutputType = g.defaultOutputType
+	}
+	if option.OutputFormat == "" {
+		option.OutputFormat = g.defaultOutputFormat
+	}
+	return option
+}
+
+// checkOutput reports known-bad combinations of outputType, outputFormat,
+// outputQuality and lossless.
+func checkOutput(i int, option RunwareOptions) []error {
+	var errs []error
+	allowed, known := compatibleOutputFormats[option.OutputType]
+	if known && (option.OutputFormat == "" || option.OutputFormat.IsValid()) && !slices.Contains(allowed, option.OutputFormat) {
+		errs = append(errs, fmt.Errorf("task %d: outputType %q cannot be combined with outputFormat %q, allowed outputFormat values are %v",
+			i, option.OutputType, option.OutputFormat, allowed))
+	}
+	if option.Lossless {
+		if option.OutputFormat != WEBP {
+			errs = append(errs, fmt.Errorf("task %d: lossless only applies to outputFormat %q, got %q", i, WEBP, option.OutputFormat))
+		}
+		if option.OutputQuality != 0 {
+			errs = append(errs, fmt.Errorf("task %d: outputQuality cannot be combined with lossless", i))
+		}
+	}
+	if option.OutputQuality == 0 {
+		return errs
+	}
+	if option.OutputQuality < MinOutputQuality || option.OutputQuality > MaxOutputQuality {
+		errs = append(errs, fmt.Errorf("task %d: outputQuality %d is outside the allowed range [%d, %d]",
+			i, option.OutputQuality, MinOutputQuality, MaxOutputQuality))
+	}
+	if option.OutputFormat.IsValid() && !slices.Contains(lossyOutputFormats, option.OutputFormat) {
+		errs = append(errs, fmt.Errorf("task %d: outputQuality cannot be combined with outputFormat %q, it only applies to %v",
+			i, option.OutputFormat, lossyOutputFormats))
+	}
+	return errs
+}
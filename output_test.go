@@ -0,0 +1,79 @@
+package runware
+
+import "testing"
+
+func TestDefaultOutput(t *testing.T) {
+	api := newFakeAPI(t, echoResults)
+	client := api.client(WithDefaultOutput(Base64Data, WEBP)).Config([]map[string]any{
+		testTask(nil),
+		testTask(map[string]any{"outputType": URL}),
+		testTask(map[string]any{"outputFormat": PNG}),
+		testTask(map[string]any{"outputType": DataURI, "outputFormat": JPG}),
+	})
+	if _, err := client.GenerateV1(); err != nil {
+		t.Fatal(err)
+	}
+	want := [][2]string{
+		{"base64Data", "WEBP"},
+		{"URL", "WEBP"},
+		{"base64Data", "PNG"},
+		{"dataURI", "JPEG"},
+	}
+	for i, task := range api.lastTasks(t) {
+		if got := [2]string{task["outputType"].(string), task["outputFormat"].(string)}; got != want[i] {
+			t.Errorf("task %d: output %v, want %v", i, got, want[i])
+		}
+	}
+}
+
+func TestNoDefaultOutputLeavesFieldsUnset(t *testing.T) {
+	api := newFakeAPI(t, echoResults)
+	if _, err := api.client().Config([]map[string]any{testTask(nil)}).GenerateV1(); err != nil {
+		t.Fatal(err)
+	}
+	task := api.lastTasks(t)[0]
+	for _, key := range []string{"outputType", "outputFormat"} {
+		if value, ok := task[key]; ok {
+			t.Errorf("%s = %v, want it left to the API", key, value)
+		}
+	}
+}
+
+func TestLosslessOnlyForWEBP(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  map[string]any
+		wantErr bool
+		want    any
+	}{
+		{"webp lossless", map[string]any{"outputFormat": WEBP, "lossless": true}, false, true},
+		{"webp lossy", map[string]any{"outputFormat": WEBP, "lossless": false}, false, nil},
+		{"webp unset", map[string]any{"outputFormat": WEBP}, false, nil},
+		{"png lossless", map[string]any{"outputFormat": PNG, "lossless": true}, true, nil},
+		{"jpeg lossless", map[string]any{"outputFormat": JPG, "lossless": true}, true, nil},
+		{"no format lossless", map[string]any{"lossless": true}, true, nil},
+		{"webp lossless with quality", map[string]any{"outputFormat": WEBP, "lossless": true, "outputQuality": 80}, true, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newFakeAPI(t, echoResults)
+			_, err := api.client().Config([]map[string]any{testTask(tt.values)}).GenerateV1()
+			if tt.wantErr {
+				if err == nil {
+					t.Error("GenerateV1 accepted lossless")
+				}
+				if api.requests() != 0 {
+					t.Error("an invalid task was sent")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, ok := api.lastTasks(t)[0]["lossless"]
+			if tt.want == nil && ok || tt.want != nil && got != tt.want {
+				t.Errorf("lossless = %v (present %v), want %v", got, ok, tt.want)
+			}
+		})
+	}
+}
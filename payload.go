@@ -0,0 +1,137 @@
+package runware
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"unicode/utf8"
+)
+
+// imageField is an image parameter of a task, which can be large enough to be
+// worth streaming rather than encoding into a buffer.
+type imageField struct {
+	name string
+	get  func(*RunwareOptions) any
+	drop func(*RunwareOptions)
+}
+
+var imageFields = []imageField{
+	{"seedImage", func(o *RunwareOptions) any { return o.SeedImage }, func(o *RunwareOptions) { o.SeedImage = "" }},
+	{"maskImage", func(o *RunwareOptions) any { return o.MaskImage }, func(o *RunwareOptions) { o.MaskImage = "" }},
+	{"inputImage", func(o *RunwareOptions) any { return o.InputImage }, func(o *RunwareOptions) { o.InputImage = "" }},
+	{"inputImages", func(o *RunwareOptions) any { return o.InputImages }, func(o *RunwareOptions) { o.InputImages = nil }},
+}
+
+// streamChunk is how much of an image string is escaped at a time.
+const streamChunk = 32 << 10
+
+// writePayload writes the JSON array of tasks to w one task at a time. The
+// image fields of each task are copied straight from the task's strings, so
+// the only buffers are one task without its images and one chunk of image.
+func writePayload(w io.Writer, g *generateImagesV1Impl, payload []RunwareOptions) error {
+	bw := bufio.NewWriterSize(w, streamChunk)
+	bw.WriteByte('[')
+	for i, task := range payload {
+		if i > 0 {
+			bw.WriteByte(',')
+		}
+		if err := writeTask(bw, g, task); err != nil {
+			return err
+		}
+	}
+	bw.WriteByte(']')
+	return bw.Flush()
+}
+
+// writeTask writes one task: the small fields through encoding/json, then the
+// image fields streamed after them. Images that Raw or WithExtraFields could
+// replace are left to the regular encoding so precedence is unchanged.
+func writeTask(w *bufio.Writer, g *generateImagesV1Impl, task RunwareOptions) error {
+	type image struct {
+		name  string
+		value any
+	}
+	var images []image
+	for _, field := range imageFields {
+		_, extra := g.extraFields[field.name]
+		_, raw := task.Raw[field.name]
+		if extra || raw {
+			continue
+		}
+		switch value := field.get(&task).(type) {
+		case string:
+			if value == "" {
+				continue
+			}
+		case []string:
+			if len(value) == 0 {
+				continue
+			}
+		}
+		images = append(images, image{field.name, field.get(&task)})
+		field.drop(&task)
+	}
+	encoded, err := json.Marshal(g.encodedTask(task))
+	if err != nil {
+		return err
+	}
+	if len(images) == 0 {
+		_, err := w.Write(encoded)
+		return err
+	}
+	// Reopen the object to append the images to it.
+	w.Write(encoded[:len(encoded)-1])
+	for i, img := range images {
+		if i > 0 || len(encoded) > 2 {
+			w.WriteByte(',')
+		}
+		writeJSONString(w, img.name)
+		w.WriteByte(':')
+		switch value := img.value.(type) {
+		case string:
+			writeJSONString(w, value)
+		case []string:
+			w.WriteByte('[')
+			for j, s := range value {
+				if j > 0 {
+					w.WriteByte(',')
+				}
+				writeJSONString(w, s)
+			}
+			w.WriteByte(']')
+		}
+	}
+	return w.WriteByte('}')
+}
+
+// writeJSONString writes s as a JSON string, escaping it a chunk at a time.
+// Base64 data needs no escaping and is copied as is.
+func writeJSONString(w *bufio.Writer, s string) {
+	w.WriteByte('"')
+	for len(s) > 0 {
+		end := min(streamChunk, len(s))
+		for end < len(s) && !utf8.RuneStart(s[end]) {
+			end++
+		}
+		chunk := s[:end]
+		s = s[end:]
+		if !needsEscape(chunk) {
+			w.WriteString(chunk)
+			continue
+		}
+		escaped, _ := json.Marshal(chunk)
+		w.Write(escaped[1 : len(escaped)-1])
+	}
+	w.WriteByte('"')
+}
+
+// needsEscape reports whether encoding/json would change any byte of s.
+func needsEscape(s string) bool {
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c < 0x20, c == '"', c == '\\', c == '<', c == '>', c == '&', c >= utf8.RuneSelf:
+			return true
+		}
+	}
+	return false
+}
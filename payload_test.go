@@ -0,0 +1,126 @@
+package runware
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestWritePayloadMatchesMarshal(t *testing.T) {
+	mask := 8
+	tests := []struct {
+		name  string
+		opts  []Option
+		tasks []RunwareOptions
+	}{
+		{"plain", nil, []RunwareOptions{{TaskType: ImageInference, TaskUUID: "a", Prompt: "p"}}},
+		{"images", nil, []RunwareOptions{{
+			TaskType: ImageInference, TaskUUID: "a", Prompt: "p",
+			SeedImage: "data:image/png;base64," + strings.Repeat("QUJD", 30000), MaskImage: "TUFTSw==", MaskMargin: &mask,
+		}}},
+		{"input images", nil, []RunwareOptions{{TaskType: PhotoMaker, TaskUUID: "a", Prompt: "p", InputImages: []string{"QQ==", "Qg=="}}}},
+		{"escaping across chunks", nil, []RunwareOptions{{
+			TaskType: ImageUpscale, TaskUUID: "a", InputImage: strings.Repeat("é<\"\\\n", streamChunk/3),
+		}}},
+		{"several tasks", nil, []RunwareOptions{
+			{TaskType: ImageInference, TaskUUID: "a", Prompt: "p", SeedImage: "QUJD"},
+			{TaskType: ImageInference, TaskUUID: "b", Prompt: "q"},
+		}},
+		{"raw keeps precedence", nil, []RunwareOptions{{
+			TaskType: ImageInference, TaskUUID: "a", Prompt: "p", SeedImage: "QUJD", Raw: map[string]any{"seedImage": "other", "acceleration": "high"},
+		}}},
+		{"extra fields", []Option{WithExtraFields(map[string]any{"maskImage": "override", "cfgScale": 7.5})}, []RunwareOptions{{
+			TaskType: ImageInference, TaskUUID: "a", Prompt: "p", SeedImage: "QUJD", MaskImage: "TUFTSw==",
+		}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGenerateImagesV1(testAPIKey, tt.opts...).(*generateImagesV1Impl)
+			want, err := json.Marshal(g.withExtraFields(tt.tasks))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got bytes.Buffer
+			if err := writePayload(&got, g, tt.tasks); err != nil {
+				t.Fatal(err)
+			}
+			var wantValue, gotValue any
+			if err := json.Unmarshal(want, &wantValue); err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal(got.Bytes(), &gotValue); err != nil {
+				t.Fatalf("streamed payload is not valid JSON: %v", err)
+			}
+			if !reflect.DeepEqual(gotValue, wantValue) {
+				t.Errorf("streamed payload differs:\n got %.300s\nwant %.300s", got.Bytes(), want)
+			}
+		})
+	}
+}
+
+func TestEncodePayloadStreamsWithoutCopyingImages(t *testing.T) {
+	const imageSize = 32 << 20
+	seed := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{0xab}, imageSize/4*3))
+	g := NewGenerateImagesV1(testAPIKey).(*generateImagesV1Impl)
+	tasks := []RunwareOptions{{TaskType: ImageInference, TaskUUID: "a", Prompt: "p", SeedImage: seed}}
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	body, err := encodePayload(g, tasks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := io.Copy(io.Discard, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	runtime.ReadMemStats(&after)
+
+	if n < imageSize {
+		t.Fatalf("body is %d bytes, want at least %d", n, imageSize)
+	}
+	// Buffering the body would allocate at least the size of the image.
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > imageSize/8 {
+		t.Errorf("encoding allocated %d bytes for a %d byte image, want under %d", allocated, imageSize, imageSize/8)
+	}
+}
+
+func TestEncodePayloadBuffersSmallPayloads(t *testing.T) {
+	g := NewGenerateImagesV1(testAPIKey).(*generateImagesV1Impl)
+	body, err := encodePayload(g, []RunwareOptions{{TaskType: ImageInference, TaskUUID: "a", SeedImage: "QUJD"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := body.(*bytes.Reader); !ok {
+		t.Errorf("small payload body is %T, want a buffered *bytes.Reader", body)
+	}
+}
+
+func TestNumberOfResultsOmittedWhenUnset(t *testing.T) {
+	tests := []struct {
+		name    string
+		results any
+		want    any
+	}{
+		{"unset", nil, nil},
+		{"zero", 0, nil},
+		{"one", 1, float64(1)},
+		{"four", 4, float64(4)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newFakeAPI(t, echoResults)
+			api.client().Config([]map[string]any{testTask(map[string]any{"results": tt.results})}).GenerateV1()
+			got, ok := api.lastTasks(t)[0]["numberOfResults"]
+			if tt.want == nil && ok || tt.want != nil && got != tt.want {
+				t.Errorf("numberOfResults = %v (present %v), want %v", got, ok, tt.want)
+			}
+		})
+	}
+}
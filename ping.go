@@ -0,0 +1,37 @@
+package runware
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// pingPayload is the API's ping task, which checks the key and connection
+// without generating anything.
+var pingPayload = []map[string]any{{"taskType": "ping", "ping": true}}
+
+// Ping sends the API's ping task to check connectivity and the API key, for
+// example at startup to fail fast on a bad key. It does not generate an image,
+// though the API may count it as a request against rate limits or a minimal
+// quota. A rejected key is reported as a *RunwareAPIError matching
+// ErrUnauthorized or ErrForbidden, and a network failure as a
+// *TransportError matching ErrConnection or ErrTimeout. Endpoints set by
+// WithEndpoints are tried in order, as for GenerateV1, and transient failures
+// are retried as set by WithRetries within the bound of WithMaxElapsed.
+func (g *generateImagesV1Impl) Ping(ctx context.Context) error {
+	if g.optionErr != nil {
+		return g.optionErr
+	}
+	ctx, release, err := g.bindClose(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+	body, err := json.Marshal(pingPayload)
+	if err != nil {
+		return err
+	}
+	_, err = retry(ensureCorrelationID(ctx), g, func(ctx context.Context) (*[]RunwareSuccessResponseBody, error) {
+		return postFailover(ctx, g, body)
+	}, nil)
+	return closedErr(ctx, err)
+}
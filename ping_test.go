@@ -0,0 +1,43 @@
+package runware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+const pongBody = `{"data":[{"taskType":"ping","pong":true}]}`
+
+func TestPingHonoursRetryAfter(t *testing.T) {
+	api := newFakeAPI(t, respondInTurn(
+		withHeaders(respondJSON(http.StatusTooManyRequests, `{"errors":[{"code":"rateLimitExceeded"}]}`), "Retry-After", "1"),
+		respondJSON(http.StatusOK, pongBody),
+	))
+	// The backoff is far longer than the test, so only Retry-After lets
+	// the second attempt happen.
+	client := api.client(WithRetries(1, time.Hour))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx); err != nil {
+		t.Fatalf("Ping: %v", err)
+	}
+	if n := api.requests(); n != 2 {
+		t.Errorf("sent %d requests, want 2", n)
+	}
+}
+
+func TestPingRetryBudgetExhausted(t *testing.T) {
+	api := newFakeAPI(t, respondJSON(http.StatusServiceUnavailable, `{"errors":[{"message":"down"}]}`))
+	client := api.client(WithRetries(5, time.Hour), WithMaxElapsed(100*time.Millisecond))
+	err := client.Ping(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "retry budget exhausted after 1 attempts") {
+		t.Fatalf("Ping error = %v, want the retry budget exhausted", err)
+	}
+	var apiErr *RunwareAPIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Ping error = %v, want the 503 wrapped", err)
+	}
+}
@@ -0,0 +1,119 @@
+package runware
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"github.com/google/uuid"
+)
+
+// Pool generates tasks read from a channel with a fixed number of workers
+// sharing one client, and with it its transport, retries, circuit breaker,
+// metrics, cost budget and response history; closing the client stops the
+// pool's requests. Each task is sent as its own request. A Pool must not be copied
+// after first use.
+type Pool struct {
+	// Client sends the tasks; its configured tasks are ignored.
+	Client GenerateImagesV1
+	// Workers is how many tasks are in flight at once, one if less than one.
+	Workers int
+	// OnResult, if set, receives the results of every task that succeeded.
+	OnResult func(task RunwareOptions, results []RunwareSuccessResponseBody)
+	// OnError, if set, receives the error of every task that failed.
+	OnError func(task RunwareOptions, err error)
+
+	submitted, succeeded, failed atomic.Int64
+}
+
+// PoolStats counts the tasks a Pool has taken and their outcomes.
+type PoolStats struct {
+	Submitted, Succeeded, Failed int64
+}
+
+// Stats returns the pool's counters so far. It is safe to call while Run is
+// running, e.g. to export them as metrics.
+func (p *Pool) Stats() PoolStats {
+	return PoolStats{
+		Submitted: p.submitted.Load(),
+		Succeeded: p.succeeded.Load(),
+		Failed:    p.failed.Load(),
+	}
+}
+
+// Run sends every task received from tasks until it is closed or ctx is done.
+// Callbacks may be called from several workers at once. When ctx is done no
+// more tasks are taken, but those already sent are left to finish and report
+// their outcome before Run returns ctx's error; a source that must not lose
+// tasks should stop sending and close tasks instead. Tasks without a
+// taskUUID are given a random one.
+func (p *Pool) Run(ctx context.Context, tasks <-chan RunwareOptions) error {
+	workers := max(p.Workers, 1)
+	// In-flight tasks outlive ctx so that shutting down drains them.
+	sendCtx := context.WithoutCancel(ctx)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case task, ok := <-tasks:
+					if !ok {
+						return
+					}
+					p.send(sendCtx, task)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return ctx.Err()
+}
+
+// send generates a single task with the pool's client and reports its
+// outcome.
+func (p *Pool) send(ctx context.Context, task RunwareOptions) {
+	p.submitted.Add(1)
+	if task.TaskUUID == "" {
+		task.TaskUUID = uuid.New().String()
+	}
+	results, err := p.generate(ctx, task)
+	if err != nil {
+		p.failed.Add(1)
+		if p.OnError != nil {
+			p.OnError(task, err)
+		}
+		return
+	}
+	p.succeeded.Add(1)
+	if p.OnResult != nil {
+		p.OnResult(task, results)
+	}
+}
+
+// generate sends task as the only task of a copy of the client that shares
+// its state, as Flush does for each batch. Other implementations of
+// GenerateImagesV1 are cloned instead.
+func (p *Pool) generate(ctx context.Context, task RunwareOptions) ([]RunwareSuccessResponseBody, error) {
+	g, ok := p.Client.(*generateImagesV1Impl)
+	if !ok {
+		client := p.Client.Clone()
+		if err := client.Enqueue(task); err != nil {
+			return nil, err
+		}
+		results, err := client.Flush(ctx)
+		return results[task.TaskUUID], err
+	}
+	single := *g
+	single.options = cloneOptions([]RunwareOptions{task})
+	single.explicitUUIDs = true
+	single.configErr, single.earlyConfigErr = nil, nil
+	data, err := single.GenerateV1Context(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return *data, nil
+}
@@ -0,0 +1,83 @@
+package runware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+func poolTask() RunwareOptions {
+	return RunwareOptions{TaskType: ImageInference, Prompt: "a lighthouse at dusk", Model: "runware:100@1", Width: 512, Height: 512}
+}
+
+// runPool sends n tasks through a one-worker pool and returns the errors of
+// the tasks that failed.
+func runPool(t *testing.T, client GenerateImagesV1, n int) (PoolStats, []error) {
+	t.Helper()
+	var mu sync.Mutex
+	var errs []error
+	pool := &Pool{Client: client, OnError: func(_ RunwareOptions, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		errs = append(errs, err)
+	}}
+	tasks := make(chan RunwareOptions, n)
+	for range n {
+		tasks <- poolTask()
+	}
+	close(tasks)
+	if err := pool.Run(context.Background(), tasks); err != nil {
+		t.Fatal(err)
+	}
+	return pool.Stats(), errs
+}
+
+func TestPoolSharesClientState(t *testing.T) {
+	api := newFakeAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		var tasks []map[string]any
+		json.NewDecoder(r.Body).Decode(&tasks)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{"data": []map[string]any{{
+			"taskType": "imageInference", "taskUUID": tasks[0]["taskUUID"], "imageUUID": "image", "cost": 1.0,
+		}}})
+	})
+	client := api.client(WithCostBudget(2))
+
+	stats, errs := runPool(t, client, 4)
+	if stats.Succeeded != 2 || stats.Failed != 2 {
+		t.Errorf("stats = %+v, want 2 succeeded and 2 failed once the budget is spent", stats)
+	}
+	for _, err := range errs {
+		if !errors.Is(err, ErrBudgetExceeded) {
+			t.Errorf("err = %v, want ErrBudgetExceeded", err)
+		}
+	}
+	if api.requests() != 2 {
+		t.Errorf("server got %d requests, want 2", api.requests())
+	}
+	if client.LastResponseMeta() == nil {
+		t.Error("LastResponseMeta is nil after the pool's requests")
+	}
+}
+
+func TestPoolStopsWhenClientIsClosed(t *testing.T) {
+	api := newFakeAPI(t, echoResults)
+	client := api.client()
+	client.Close()
+
+	stats, errs := runPool(t, client, 3)
+	if stats.Failed != 3 {
+		t.Errorf("stats = %+v, want every task to fail", stats)
+	}
+	for _, err := range errs {
+		if !errors.Is(err, ErrClosed) {
+			t.Errorf("err = %v, want ErrClosed", err)
+		}
+	}
+	if api.requests() != 0 {
+		t.Errorf("server got %d requests from a closed client", api.requests())
+	}
+}
@@ -0,0 +1,72 @@
+package runware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ProgressPollInterval is the recommended wait between Progress calls for the
+// same task. Polling faster does not make the task finish sooner and counts
+// against rate limits.
+const ProgressPollInterval = 2 * time.Second
+
+// Progress asks the API, with its getResponse task, how far the task with
+// taskUUID has got, from 0 to 1. The API reports tasks as processing or done
+// rather than a percentage, so the fraction is the share of the task's
+// results that are ready: 0 or 1 for a single result. A task the API reports
+// as failed is returned as an error. Poll every ProgressPollInterval or so.
+func (g *generateImagesV1Impl) Progress(taskUUID string) (float64, error) {
+	if taskUUID == "" {
+		return 0, errors.New("taskUUID must not be empty")
+	}
+	ctx, release, err := g.bindClose(context.Background())
+	if err != nil {
+		return 0, err
+	}
+	defer release()
+	body, err := json.Marshal([]map[string]any{{"taskType": "getResponse", "taskUUID": taskUUID}})
+	if err != nil {
+		return 0, err
+	}
+	data, err := postFailover(ensureCorrelationID(ctx), g, body)
+	if errors.Is(err, ErrNoResults) {
+		// Nothing is ready yet.
+		return 0, nil
+	}
+	if err != nil {
+		return 0, closedErr(ctx, err)
+	}
+	var total, done int
+	for _, result := range *data {
+		if result.TaskUUID != "" && result.TaskUUID != taskUUID {
+			continue
+		}
+		total++
+		switch resultStatus(result) {
+		case "error":
+			return 0, fmt.Errorf("task %s failed", taskUUID)
+		case "success":
+			done++
+		}
+	}
+	if total == 0 {
+		return 0, nil
+	}
+	return float64(done) / float64(total), nil
+}
+
+// resultStatus returns the status getResponse gave a result. Results without
+// one count as done once they carry an image.
+func resultStatus(result RunwareSuccessResponseBody) string {
+	var status string
+	if raw, ok := result.Extra["status"]; ok && json.Unmarshal(raw, &status) == nil {
+		return status
+	}
+	if result.ImageUUID != "" || result.ImageUrl != "" || result.ImageBase64Data != "" || result.ImageDataURI != "" {
+		return "success"
+	}
+	return "processing"
+}
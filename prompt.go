@@ -0,0 +1,128 @@
+package runware
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxPromptLength is the longest prompt, in characters, the API accepts.
+const MaxPromptLength = 3000
+
+// checkPrompt reports a prompt that exceeds MaxPromptLength, unless
+// WithPromptTruncation is enabled.
+func (g *generateImagesV1Impl) checkPrompt(i int, name, prompt string) error {
+	length := utf8.RuneCountInString(prompt)
+	if length <= MaxPromptLength || g.truncatePrompts {
+		return nil
+	}
+	return fmt.Errorf("task %d: %s is %d characters, %d over the limit of %d",
+		i, name, length, length-MaxPromptLength, MaxPromptLength)
+}
+
+// requiresPrompt reports whether tasks of taskType must have a positive
+// prompt. Task types such as upscaling or background removal work from an
+// input image alone.
+func requiresPrompt(taskType TaskType) bool {
+	return taskType == ImageInference || taskType == PhotoMaker
+}
+
+// checkPromptPresent reports a missing prompt before the API does. A prompt
+// of only whitespace counts as missing.
+func checkPromptPresent(i int, option RunwareOptions) error {
+	if !requiresPrompt(option.TaskType) || strings.TrimSpace(option.Prompt) != "" {
+		return nil
+	}
+	return fmt.Errorf("task %d: prompt is required for %s tasks", i, option.TaskType)
+}
+
+// prompt returns the prompt to send, truncated to MaxPromptLength when
+// WithPromptTruncation is enabled.
+func (g *generateImagesV1Impl) prompt(i int, name, prompt string) string {
+	if !g.truncatePrompts || utf8.RuneCountInString(prompt) <= MaxPromptLength {
+		return prompt
+	}
+	log.Printf("task %d: truncating %s to %d characters", i, name, MaxPromptLength)
+	return string([]rune(prompt)[:MaxPromptLength])
+}
+
+// PromptWeighting selects the syntax used to weight parts of a prompt.
+type PromptWeighting string
+
+const (
+	// Compel weights a group as "(word)1.2" or with "+"/"-" suffixes.
+	Compel PromptWeighting = "compel"
+	// SDEmbeds weights a group as "(word:1.2)".
+	SDEmbeds PromptWeighting = "sdEmbeds"
+)
+
+// ValidatePromptSyntax checks that prompt's emphasis syntax is well formed for
+// the given weighting mode: parentheses must balance and every weight must be
+// a number. Prompts sent without a weighting mode are only checked for
+// balanced parentheses.
+func ValidatePromptSyntax(prompt string, mode PromptWeighting) error {
+	depth := 0
+	for pos := 0; pos < len(prompt); pos++ {
+		switch prompt[pos] {
+		case '(':
+			depth++
+		case ')':
+			if depth == 0 {
+				return fmt.Errorf("unbalanced ')' at position %d", pos)
+			}
+			depth--
+			if mode == Compel {
+				weight := leadingNumber(prompt[pos+1:])
+				if weight != "" {
+					if _, err := strconv.ParseFloat(weight, 64); err != nil {
+						return fmt.Errorf("invalid weight %q after position %d", weight, pos)
+					}
+				}
+			}
+		case ':':
+			if mode == SDEmbeds && depth > 0 {
+				end := strings.IndexByte(prompt[pos:], ')')
+				if end < 0 {
+					return fmt.Errorf("unterminated weight at position %d", pos)
+				}
+				weight := strings.TrimSpace(prompt[pos+1 : pos+end])
+				if _, err := strconv.ParseFloat(weight, 64); err != nil {
+					return fmt.Errorf("invalid weight %q at position %d", weight, pos)
+				}
+			}
+		}
+	}
+	if depth != 0 {
+		return fmt.Errorf("%d unclosed '('", depth)
+	}
+	return nil
+}
+
+// leadingNumber returns the run of digits and dots at the start of s.
+func leadingNumber(s string) string {
+	end := strings.IndexFunc(s, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if end < 0 {
+		return s
+	}
+	return s[:end]
+}
+
+// checkPromptSyntax validates both prompts of a task when
+// WithPromptSyntaxValidation is enabled.
+func (g *generateImagesV1Impl) checkPromptSyntax(i int, option RunwareOptions) []error {
+	if !g.validatePromptSyntax {
+		return nil
+	}
+	var errs []error
+	if err := ValidatePromptSyntax(option.Prompt, option.PromptWeighting); err != nil {
+		errs = append(errs, fmt.Errorf("task %d: prompt: %w", i, err))
+	}
+	if err := ValidatePromptSyntax(option.NegativePrompt, option.PromptWeighting); err != nil {
+		errs = append(errs, fmt.Errorf("task %d: negativePrompt: %w", i, err))
+	}
+	return errs
+}
@@ -0,0 +1,112 @@
+package runware
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// PromptBuilder assembles the prompt and negative prompt of a task from parts,
+// writing weights in the syntax of its weighting mode so they do not have to
+// be concatenated by hand:
+//
+//	b := runware.NewPromptBuilder(runware.SDEmbeds).
+//		Add("a dragon", 1.3).
+//		Add("mountains", 1).
+//		AddNegative("blurry")
+//	prompt, negative := b.Prompt(), b.NegativePrompt() // "(a dragon:1.3), mountains", "blurry"
+//
+// Parts are joined with ", ". Problems such as a weight without a weighting
+// mode are kept and reported by Err.
+type PromptBuilder struct {
+	mode     PromptWeighting
+	positive []string
+	negative []string
+	err      error
+}
+
+// NewPromptBuilder returns a builder writing weights for mode. With an empty
+// mode only parts of weight 1 can be added.
+func NewPromptBuilder(mode PromptWeighting) *PromptBuilder {
+	return &PromptBuilder{mode: mode}
+}
+
+// Add appends text to the prompt with the given weight, where 1 leaves it
+// unweighted.
+func (b *PromptBuilder) Add(text string, weight float64) *PromptBuilder {
+	part, err := b.weighted(text, weight)
+	if err != nil {
+		b.err = errors.Join(b.err, err)
+		return b
+	}
+	b.positive = append(b.positive, part)
+	return b
+}
+
+// AddNegative appends text to the negative prompt.
+func (b *PromptBuilder) AddNegative(text string) *PromptBuilder {
+	if err := b.checkText(text); err != nil {
+		b.err = errors.Join(b.err, err)
+		return b
+	}
+	b.negative = append(b.negative, strings.TrimSpace(text))
+	return b
+}
+
+// Prompt returns the assembled prompt.
+func (b *PromptBuilder) Prompt() string {
+	return strings.Join(b.positive, ", ")
+}
+
+// NegativePrompt returns the assembled negative prompt.
+func (b *PromptBuilder) NegativePrompt() string {
+	return strings.Join(b.negative, ", ")
+}
+
+// Weighting returns the mode the prompts were written for, to be given as the
+// task's promptWeighting.
+func (b *PromptBuilder) Weighting() PromptWeighting {
+	return b.mode
+}
+
+// Err returns the problems met while adding parts, which were left out of the
+// prompts, or nil if there were none.
+func (b *PromptBuilder) Err() error {
+	return b.err
+}
+
+func (b *PromptBuilder) weighted(text string, weight float64) (string, error) {
+	if err := b.checkText(text); err != nil {
+		return "", err
+	}
+	text = strings.TrimSpace(text)
+	if math.IsNaN(weight) || math.IsInf(weight, 0) || weight <= 0 {
+		return "", fmt.Errorf("prompt part %q: weight must be a positive number, got %v", text, weight)
+	}
+	if weight == 1 {
+		return text, nil
+	}
+	formatted := strconv.FormatFloat(weight, 'f', -1, 64)
+	switch b.mode {
+	case Compel:
+		return "(" + text + ")" + formatted, nil
+	case SDEmbeds:
+		return "(" + text + ":" + formatted + ")", nil
+	default:
+		return "", fmt.Errorf("prompt part %q: weight %s needs a weighting mode", text, formatted)
+	}
+}
+
+// checkText rejects empty parts and parts whose own emphasis syntax is broken,
+// which would break the rest of the prompt.
+func (b *PromptBuilder) checkText(text string) error {
+	if strings.TrimSpace(text) == "" {
+		return errors.New("prompt part must not be empty")
+	}
+	if err := ValidatePromptSyntax(text, b.mode); err != nil {
+		return fmt.Errorf("prompt part %q: %w", text, err)
+	}
+	return nil
+}
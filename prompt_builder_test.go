@@ -0,0 +1,70 @@
+package runware
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPromptBuilderGolden(t *testing.T) {
+	tests := []struct {
+		name         string
+		mode         PromptWeighting
+		build        func(*PromptBuilder)
+		prompt       string
+		negative     string
+		errSubstring string
+	}{
+		{
+			name: "compel",
+			mode: Compel,
+			build: func(b *PromptBuilder) {
+				b.Add("a dragon", 1.3).Add(" mountains ", 1).Add("fog", 0.5).AddNegative("blurry").AddNegative("low quality")
+			},
+			prompt:   "(a dragon)1.3, mountains, (fog)0.5",
+			negative: "blurry, low quality",
+		},
+		{
+			name: "sdEmbeds",
+			mode: SDEmbeds,
+			build: func(b *PromptBuilder) {
+				b.Add("a dragon", 1.3).Add("mountains", 1).Add("fog", 0.25).AddNegative("blurry")
+			},
+			prompt:   "(a dragon:1.3), mountains, (fog:0.25)",
+			negative: "blurry",
+		},
+		{
+			name: "no mode",
+			build: func(b *PromptBuilder) {
+				b.Add("a dragon", 1).Add("mountains", 1.3).AddNegative("blurry")
+			},
+			prompt:       "a dragon",
+			negative:     "blurry",
+			errSubstring: `prompt part "mountains": weight 1.3 needs a weighting mode`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewPromptBuilder(tt.mode)
+			tt.build(b)
+			if got := b.Prompt(); got != tt.prompt {
+				t.Errorf("Prompt() = %q, want %q", got, tt.prompt)
+			}
+			if got := b.NegativePrompt(); got != tt.negative {
+				t.Errorf("NegativePrompt() = %q, want %q", got, tt.negative)
+			}
+			if b.Weighting() != tt.mode {
+				t.Errorf("Weighting() = %q, want %q", b.Weighting(), tt.mode)
+			}
+			err := b.Err()
+			if tt.errSubstring == "" {
+				if err != nil {
+					t.Errorf("Err() = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errSubstring) {
+				t.Errorf("Err() = %v, want an error containing %q", err, tt.errSubstring)
+			}
+		})
+	}
+}
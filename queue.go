@@ -0,0 +1,98 @@
+package runware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// DefaultQueueCapacity is how many tasks Enqueue buffers, and
+// DefaultBatchSize how many of them Flush sends per request, unless WithQueue
+// says otherwise.
+const (
+	DefaultQueueCapacity = 1000
+	DefaultBatchSize     = 20
+)
+
+// flushConcurrency bounds how many batches Flush sends at once.
+const flushConcurrency = 4
+
+// ErrQueueFull is returned by Enqueue when the buffer holds as many tasks as
+// WithQueue allows. Flush to make room.
+var ErrQueueFull = errors.New("task queue is full")
+
+// queuedTask is a task buffered by Enqueue, remembering whether its UUID was
+// given or generated.
+type queuedTask struct {
+	options      RunwareOptions
+	explicitUUID bool
+}
+
+// Enqueue buffers task to be sent by the next Flush, independently of the
+// tasks given to Config. A task without a taskUUID is given a random one. It
+// is safe to call from several goroutines.
+func (g *generateImagesV1Impl) Enqueue(task RunwareOptions) error {
+	queued := queuedTask{options: cloneOptions([]RunwareOptions{task})[0], explicitUUID: task.TaskUUID != ""}
+	if !queued.explicitUUID {
+		queued.options.TaskUUID = uuid.New().String()
+	}
+	g.state.mu.Lock()
+	defer g.state.mu.Unlock()
+	if len(g.state.queue) >= g.queueCapacity {
+		return fmt.Errorf("%w (%d tasks)", ErrQueueFull, g.queueCapacity)
+	}
+	g.state.queue = append(g.state.queue, queued)
+	return nil
+}
+
+// Flush sends every task buffered by Enqueue, split into batches of the size
+// set by WithQueue that are sent in parallel like separate GenerateV1Context
+// calls, and returns the results keyed by taskUUID. The buffer is emptied
+// whether or not the batches succeed; when some fail, the results of the
+// others are returned together with the failures joined into one error.
+func (g *generateImagesV1Impl) Flush(ctx context.Context) (map[string][]RunwareSuccessResponseBody, error) {
+	g.state.mu.Lock()
+	queue := g.state.queue
+	g.state.queue = nil
+	g.state.mu.Unlock()
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		results = make(map[string][]RunwareSuccessResponseBody)
+		errs    []error
+		slots   = make(chan struct{}, flushConcurrency)
+	)
+	for start := 0; start < len(queue); start += g.batchSize {
+		batch := *g
+		batch.options = make([]RunwareOptions, 0, g.batchSize)
+		batch.explicitUUIDs = true
+		for _, task := range queue[start:min(start+g.batchSize, len(queue))] {
+			batch.options = append(batch.options, task.options)
+			batch.explicitUUIDs = batch.explicitUUIDs && task.explicitUUID
+		}
+		batch.configErr, batch.earlyConfigErr = nil, nil
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			data, err := batch.GenerateV1Context(ctx)
+			mu.Lock()
+			defer mu.Unlock()
+			if data != nil {
+				for _, image := range *data {
+					results[image.TaskUUID] = append(results[image.TaskUUID], image)
+				}
+			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("batch of tasks %d to %d: %w", start, start+len(batch.options)-1, err))
+			}
+		}()
+	}
+	wg.Wait()
+	return results, errors.Join(errs...)
+}
@@ -0,0 +1,66 @@
+package runware
+
+import (
+	"fmt"
+	"math"
+)
+
+// CFG scale bounds accepted by the API.
+const (
+	MinCFGScale = 0
+	MaxCFGScale = 50
+)
+
+// numericRange is the documented range of a numeric task parameter. Both
+// bounds are inclusive.
+type numericRange struct {
+	name     string
+	min, max float64
+	// value returns the parameter and whether it is set.
+	value func(option RunwareOptions) (float64, bool)
+}
+
+// numericRanges is the one place the API's numeric limits are kept.
+var numericRanges = []numericRange{
+	{"steps", 1, MaxSteps, func(o RunwareOptions) (float64, bool) { return float64(o.Steps), o.Steps != 0 }},
+	{"CFGScale", MinCFGScale, MaxCFGScale, optionalFloat(func(o RunwareOptions) *float64 { return o.CFGScale })},
+	{"clipSkip", 0, 2, func(o RunwareOptions) (float64, bool) {
+		if o.ClipSkip == nil {
+			return 0, false
+		}
+		return float64(*o.ClipSkip), true
+	}},
+	{"strength", 0, 1, optionalFloat(func(o RunwareOptions) *float64 { return o.Strength })},
+}
+
+func optionalFloat(field func(RunwareOptions) *float64) func(RunwareOptions) (float64, bool) {
+	return func(o RunwareOptions) (float64, bool) {
+		if v := field(o); v != nil {
+			return *v, true
+		}
+		return 0, false
+	}
+}
+
+// checkRanges reports every set parameter outside its range. Steps above
+// MaxSteps are let through when WithStepClamping will lower them anyway.
+func (g *generateImagesV1Impl) checkRanges(i int, option RunwareOptions) []error {
+	var errs []error
+	for _, r := range numericRanges {
+		value, ok := r.value(option)
+		if !ok {
+			continue
+		}
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			errs = append(errs, fmt.Errorf("task %d: %s must be a finite number, got %v", i, r.name, value))
+			continue
+		}
+		if r.name == "steps" && value > r.max && g.clampSteps {
+			continue
+		}
+		if value < r.min || value > r.max {
+			errs = append(errs, fmt.Errorf("task %d: %s %v is outside the allowed range [%v, %v]", i, r.name, value, r.min, r.max))
+		}
+	}
+	return errs
+}
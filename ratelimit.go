@@ -0,0 +1,60 @@
+package runware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RateLimitInfo is the rate-limit state reported by the API's
+// x-ratelimit-* response headers.
+type RateLimitInfo struct {
+	Limit     int
+	Remaining int
+	// Reset is when the current window ends, or zero when not reported.
+	Reset time.Time
+}
+
+// parseRateLimit extracts RateLimitInfo from response headers. It returns nil
+// when none of the headers are present.
+func parseRateLimit(header http.Header) *RateLimitInfo {
+	limit := header.Get("X-Ratelimit-Limit")
+	remaining := header.Get("X-Ratelimit-Remaining")
+	reset := header.Get("X-Ratelimit-Reset")
+	if limit == "" && remaining == "" && reset == "" {
+		return nil
+	}
+	info := &RateLimitInfo{}
+	info.Limit, _ = strconv.Atoi(strings.TrimSpace(limit))
+	info.Remaining, _ = strconv.Atoi(strings.TrimSpace(remaining))
+	info.Reset = parseRateLimitReset(reset)
+	return info
+}
+
+// parseRateLimitReset accepts seconds until the reset, a Unix timestamp in
+// seconds, or an HTTP date.
+func parseRateLimitReset(value string) time.Time {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		// Values this large cannot be a wait in seconds; they are timestamps.
+		if seconds > 1e9 {
+			return time.Unix(seconds, 0)
+		}
+		return time.Now().Add(time.Duration(seconds) * time.Second)
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return date
+	}
+	return time.Time{}
+}
+
+func (g *generateImagesV1Impl) LastRateLimit() *RateLimitInfo {
+	if meta := g.LastResponseMeta(); meta != nil {
+		return meta.RateLimit
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package runware
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+// withHeaders runs respond after setting the given header pairs.
+func withHeaders(respond http.HandlerFunc, header ...string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		for i := 0; i+1 < len(header); i += 2 {
+			w.Header().Set(header[i], header[i+1])
+		}
+		respond(w, r)
+	}
+}
+
+func TestLastRateLimit(t *testing.T) {
+	reset := time.Now().Add(time.Hour).Truncate(time.Second)
+	tests := []struct {
+		name    string
+		respond http.HandlerFunc
+		want    *RateLimitInfo
+	}{
+		{
+			name:    "success",
+			respond: withHeaders(echoResults, "x-ratelimit-limit", "100", "x-ratelimit-remaining", "42", "x-ratelimit-reset", "1700000000"),
+			want:    &RateLimitInfo{Limit: 100, Remaining: 42, Reset: time.Unix(1700000000, 0)},
+		},
+		{
+			name:    "failure",
+			respond: withHeaders(respondJSON(http.StatusTooManyRequests, `{"errors":[]}`), "x-ratelimit-limit", "100", "x-ratelimit-remaining", "0", "x-ratelimit-reset", reset.UTC().Format(http.TimeFormat)),
+			want:    &RateLimitInfo{Limit: 100, Remaining: 0, Reset: reset},
+		},
+		{name: "absent", respond: echoResults, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newFakeAPI(t, tt.respond)
+			client := api.client()
+			if client.LastRateLimit() != nil {
+				t.Fatal("LastRateLimit is set before any call")
+			}
+			client.Config([]map[string]any{testTask(nil)}).GenerateV1()
+			got := client.LastRateLimit()
+			if tt.want == nil {
+				if got != nil {
+					t.Errorf("LastRateLimit = %+v, want nil", got)
+				}
+				return
+			}
+			if got == nil || got.Limit != tt.want.Limit || got.Remaining != tt.want.Remaining || !got.Reset.Equal(tt.want.Reset) {
+				t.Errorf("LastRateLimit = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRateLimitResetSeconds(t *testing.T) {
+	got := parseRateLimitReset("30")
+	if wait := time.Until(got); wait < 29*time.Second || wait > 31*time.Second {
+		t.Errorf("reset in %s, want about 30s", wait)
+	}
+}
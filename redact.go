@@ -0,0 +1,48 @@
+package runware
+
+import (
+	"fmt"
+	"strings"
+)
+
+// redacted replaces secrets wherever the package renders them.
+const redacted = "***"
+
+// Redact masks a secret for display. An Authorization header value keeps its
+// scheme, so "Bearer abc123" becomes "Bearer ***"; anything else becomes
+// "***". It is the masking the package itself applies to the API key.
+func Redact(secret string) string {
+	if scheme, _, ok := strings.Cut(secret, " "); ok {
+		return scheme + " " + redacted
+	}
+	return redacted
+}
+
+// redactKey masks every occurrence of apiKey in text.
+func redactKey(text, apiKey string) string {
+	if apiKey == "" {
+		return text
+	}
+	return strings.ReplaceAll(text, apiKey, redacted)
+}
+
+// redactedError masks the API key in the message of an error from outside the
+// package, such as a RoundTripper quoting the request's headers, while still
+// unwrapping to it.
+type redactedError struct {
+	err    error
+	apiKey string
+}
+
+func (e *redactedError) Error() string { return redactKey(e.err.Error(), e.apiKey) }
+
+func (e *redactedError) Unwrap() error { return e.err }
+
+// String describes the client without its API key, so printing the client
+// with %v or %+v cannot leak it.
+func (g *generateImagesV1Impl) String() string {
+	return fmt.Sprintf("runware.GenerateImagesV1{apiKey: %s, tasks: %d}", redacted, len(g.options))
+}
+
+// GoString does the same for %#v.
+func (g *generateImagesV1Impl) GoString() string { return g.String() }
@@ -0,0 +1,72 @@
+package runware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// roundTripFunc adapts a function to http.RoundTripper.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestErrorsNeverContainAPIKey(t *testing.T) {
+	const key = "sentinel-key-9d1c4b7e"
+	echoKey := func(status int, contentType string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", contentType)
+			w.WriteHeader(status)
+			// Proxies and APIs do echo credentials back in error bodies.
+			fmt.Fprintf(w, `{"errors":[{"code":"invalidApiKey","message":"got %s","parameter":"%s"}], "x": "%s`, r.Header.Get("Authorization"), key, key)
+		}
+	}
+	tests := []struct {
+		name    string
+		respond http.HandlerFunc
+		opts    []Option
+	}{
+		{"401 json", echoKey(http.StatusUnauthorized, "application/json"), nil},
+		{"429 json", echoKey(http.StatusTooManyRequests, "application/json"), nil},
+		{"502 html", echoKey(http.StatusBadGateway, "text/html"), nil},
+		{"200 html", echoKey(http.StatusOK, "text/html"), nil},
+		{"200 truncated json", echoKey(http.StatusOK, "application/json"), nil},
+		{"500 with retries", echoKey(http.StatusInternalServerError, "application/json"), []Option{WithRetries(1, 0)}},
+		{"dropped connection", dropConnection, nil},
+		{"transport error", echoResults, []Option{WithTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			return nil, fmt.Errorf("proxy refused %s", req.Header.Get("Authorization"))
+		}))}},
+		{"empty data", respondJSON(http.StatusOK, `{"data":[],"errors":[{"message":"key `+key+` has no credits"}]}`), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newFakeAPI(t, tt.respond)
+			opts := append([]Option{WithEndpoints([]string{api.URL})}, tt.opts...)
+			client := NewGenerateImagesV1(key, opts...).Config([]map[string]any{testTask(nil)})
+			_, err := client.GenerateV1()
+			if err == nil {
+				t.Fatal("GenerateV1 succeeded")
+			}
+			if msg := fmt.Sprintf("%v %+v", err, err); strings.Contains(msg, key) {
+				t.Errorf("error leaks the key: %s", msg)
+			}
+			if err := client.Ping(context.Background()); err != nil && strings.Contains(err.Error(), key) {
+				t.Errorf("Ping error leaks the key: %v", err)
+			}
+		})
+	}
+}
+
+func TestRedact(t *testing.T) {
+	for secret, want := range map[string]string{
+		"Bearer abc123": "Bearer ***",
+		"abc123":        "***",
+		"":              "***",
+	} {
+		if got := Redact(secret); got != want {
+			t.Errorf("Redact(%q) = %q, want %q", secret, got, want)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package runware
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"math"
+	"strings"
+
+	"github.com/google/uuid"
+	"golang.org/x/image/draw"
+)
+
+// ImageAdjustment describes an input image that WithAutoResize resized before
+// sending.
+type ImageAdjustment struct {
+	TaskUUID string
+	// Field is the task key the image was given in, seedImage or maskImage.
+	Field         string
+	Width, Height Definition
+	// OriginalWidth and OriginalHeight are the size the image was given in.
+	OriginalWidth, OriginalHeight Definition
+}
+
+// autoResize returns a copy of options whose inline seed and mask images have
+// been resized to valid dimensions, keeping their aspect ratio, along with
+// what was changed.
+// Tasks without a width or height take the size of their resized seed image.
+// Images referenced by UUID or URL, and formats that cannot be decoded here,
+// are sent unchanged.
+func autoResize(options []RunwareOptions) ([]RunwareOptions, []ImageAdjustment, error) {
+	resized := make([]RunwareOptions, len(options))
+	var adjustments []ImageAdjustment
+	for i, option := range options {
+		for _, field := range []struct {
+			name  string
+			value *string
+		}{{"seedImage", &option.SeedImage}, {"maskImage", &option.MaskImage}} {
+			encoded, adjustment, err := resizeInputImage(*field.value)
+			if err != nil {
+				return nil, nil, fmt.Errorf("task %d: %s: %w", i, field.name, err)
+			}
+			if adjustment == nil {
+				continue
+			}
+			*field.value = encoded
+			adjustment.TaskUUID = option.TaskUUID
+			adjustment.Field = field.name
+			adjustments = append(adjustments, *adjustment)
+			if field.name == "seedImage" && option.Width == 0 && option.Height == 0 {
+				option.Width, option.Height = adjustment.Width, adjustment.Height
+			}
+		}
+		resized[i] = option
+	}
+	return resized, adjustments, nil
+}
+
+// resizeInputImage resizes an inline image given as a data URI or bare base64
+// and re-encodes it the same way. Both sides are scaled by the same factor,
+// down to fit MaxDimension or up to reach MinDimension, and the result is
+// centred on the nearest valid size, padded with transparency (black in a
+// JPEG) or cropped to fit. It returns a nil adjustment when the image was left
+// alone.
+func resizeInputImage(value string) (string, *ImageAdjustment, error) {
+	data, inline, err := inlineImage(value)
+	if !inline || err != nil {
+		return value, nil, nil
+	}
+	isDataURI := strings.HasPrefix(value, "data:")
+	src, format, err := image.Decode(bytes.NewReader(data))
+	if errors.Is(err, image.ErrFormat) && !missingWEBP(data, err) {
+		return value, nil, nil
+	}
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to decode image: %w", decodeErr(data, err))
+	}
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	scale := 1.0
+	if longest := max(width, height); longest > int(MaxDimension) {
+		scale = float64(MaxDimension) / float64(longest)
+	}
+	if shortest := min(width, height); float64(shortest)*scale < float64(MinDimension) {
+		// Too narrow to fit both limits: reach the minimum and crop the
+		// long side.
+		scale = float64(MinDimension) / float64(shortest)
+	}
+	scaledWidth := max(int(math.Round(float64(width)*scale)), 1)
+	scaledHeight := max(int(math.Round(float64(height)*scale)), 1)
+	adjustment := &ImageAdjustment{
+		OriginalWidth:  toDefinition(width),
+		OriginalHeight: toDefinition(height),
+		Width:          roundDimension(toDefinition(min(scaledWidth, int(MaxDimension)))),
+		Height:         roundDimension(toDefinition(min(scaledHeight, int(MaxDimension)))),
+	}
+	if int(adjustment.Width) == width && int(adjustment.Height) == height {
+		return value, nil, nil
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, int(adjustment.Width), int(adjustment.Height)))
+	offset := image.Pt((int(adjustment.Width)-scaledWidth)/2, (int(adjustment.Height)-scaledHeight)/2)
+	draw.CatmullRom.Scale(dst, image.Rect(0, 0, scaledWidth, scaledHeight).Add(offset), src, bounds, draw.Src, nil)
+	var buf bytes.Buffer
+	mimeType := "image/png"
+	if format == "jpeg" {
+		mimeType = "image/jpeg"
+		err = jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 95})
+	} else {
+		err = png.Encode(&buf, dst)
+	}
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to encode resized image: %w", err)
+	}
+	encoded := base64.StdEncoding.EncodeToString(buf.Bytes())
+	if isDataURI {
+		encoded = "data:" + mimeType + ";base64," + encoded
+	}
+	return encoded, adjustment, nil
+}
+
+// toDefinition converts a size in pixels to a Definition, clamping it to the
+// largest one.
+func toDefinition(n int) Definition {
+	return Definition(min(n, math.MaxUint16))
+}
+
+// inlineImage decodes an input image given inline, as a data URI or bare
+// base64 in any form decodeBase64 accepts. Images referenced by UUID or URL
+// are not inline and are returned as nil.
+func inlineImage(value string) (data []byte, inline bool, err error) {
+	if value == "" || strings.HasPrefix(value, "http://") || strings.HasPrefix(value, "https://") {
+		return nil, false, nil
+	}
+	if _, err := uuid.Parse(value); err == nil {
+		return nil, false, nil
+	}
+	if strings.HasPrefix(value, "data:") {
+		data, _, err = decodeDataURI(value)
+	} else {
+		data, err = decodeBase64(value)
+	}
+	return data, true, err
+}
+
+// LastAdjustments returns the input images resized by the most recent call
+// made with WithAutoResize, or nil if none were.
+func (g *generateImagesV1Impl) LastAdjustments() []ImageAdjustment {
+	g.state.mu.Lock()
+	defer g.state.mu.Unlock()
+	return g.state.lastAdjustments
+}
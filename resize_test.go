@@ -0,0 +1,91 @@
+package runware
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+// pngBase64 encodes a width x height image filled with c as bare base64.
+func pngBase64(t *testing.T, width, height int, c color.Gray) string {
+	t.Helper()
+	img := image.NewGray(image.Rect(0, 0, width, height))
+	for i := range img.Pix {
+		img.Pix[i] = c.Y
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+func TestResizeInputImageKeepsAspectRatio(t *testing.T) {
+	tests := []struct {
+		name                                  string
+		width, height                         int
+		wantWidth, wantHeight                 Definition
+		wantOriginalWidth, wantOriginalHeight Definition
+	}{
+		{"wide", 3000, 1000, 2048, 704, 3000, 1000},
+		{"tall", 1000, 3000, 704, 2048, 1000, 3000},
+		{"small", 100, 50, 256, 128, 100, 50},
+		{"off grid", 1000, 600, 1024, 576, 1000, 600},
+		{"wider than a Definition", 70000, 128, 2048, 128, 65535, 128},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded, adjustment, err := resizeInputImage(pngBase64(t, tt.width, tt.height, color.Gray{Y: 200}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if adjustment == nil {
+				t.Fatal("image was not resized")
+			}
+			if adjustment.Width != tt.wantWidth || adjustment.Height != tt.wantHeight {
+				t.Errorf("resized to %dx%d, want %dx%d", adjustment.Width, adjustment.Height, tt.wantWidth, tt.wantHeight)
+			}
+			if adjustment.OriginalWidth != tt.wantOriginalWidth || adjustment.OriginalHeight != tt.wantOriginalHeight {
+				t.Errorf("original size %dx%d, want %dx%d", adjustment.OriginalWidth, adjustment.OriginalHeight, tt.wantOriginalWidth, tt.wantOriginalHeight)
+			}
+			data, _ := base64.StdEncoding.DecodeString(encoded)
+			img, err := png.Decode(bytes.NewReader(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if size := img.Bounds().Size(); size.X != int(tt.wantWidth) || size.Y != int(tt.wantHeight) {
+				t.Errorf("encoded image is %v, want %dx%d", size, tt.wantWidth, tt.wantHeight)
+			}
+		})
+	}
+}
+
+func TestResizeInputImagePadsInsteadOfStretching(t *testing.T) {
+	// 3000x1000 scales to 2048x683, centred on a 2048x704 canvas.
+	encoded, _, err := resizeInputImage(pngBase64(t, 3000, 1000, color.Gray{Y: 255}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := base64.StdEncoding.DecodeString(encoded)
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, _, a := img.At(1024, 0).RGBA(); a != 0 {
+		t.Errorf("top row has alpha %d, want transparent padding", a)
+	}
+	if r, _, _, a := img.At(1024, 352).RGBA(); a != 0xffff || r != 0xffff {
+		t.Errorf("centre is %v, want the opaque white source", img.At(1024, 352))
+	}
+}
+
+func TestResizeInputImageLeavesValidImagesAlone(t *testing.T) {
+	value := pngBase64(t, 512, 768, color.Gray{Y: 10})
+	encoded, adjustment, err := resizeInputImage(value)
+	if err != nil || adjustment != nil || encoded != value {
+		t.Errorf("resizeInputImage changed a valid image: adjustment %+v, err %v", adjustment, err)
+	}
+}
@@ -0,0 +1,176 @@
+package runware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// sendWithRetry sends the configured batch, retrying transient failures as
+// configured by WithRetries. Every attempt is built from the same options, so
+// the taskUUIDs never change between attempts of one logical call; the API may
+// therefore deliver the same result more than once, which is why results from
+// all attempts are merged and deduplicated.
+//
+// When WithMaxElapsed is set, attempts and the waits between them share a
+// single time budget on top of any deadline carried by ctx.
+func sendWithRetry(ctx context.Context, g *generateImagesV1Impl, urls []string) (*[]RunwareSuccessResponseBody, error) {
+	return retry(ctx, g,
+		func(ctx context.Context) (*[]RunwareSuccessResponseBody, error) {
+			return sendHedged(ctx, g, urls)
+		},
+		func(ctx context.Context, results []RunwareSuccessResponseBody, err error) (*[]RunwareSuccessResponseBody, error) {
+			return cutOff(ctx, g, results, err)
+		})
+}
+
+// retry calls send until it succeeds or fails for good, as sendWithRetry
+// describes, and returns the merged results. A final failure is handed to
+// fail, with the context the attempts ran in, to decide what to return; a nil
+// fail returns just the error.
+func retry(ctx context.Context, g *generateImagesV1Impl,
+	send func(context.Context) (*[]RunwareSuccessResponseBody, error),
+	fail func(context.Context, []RunwareSuccessResponseBody, error) (*[]RunwareSuccessResponseBody, error),
+) (*[]RunwareSuccessResponseBody, error) {
+	if fail == nil {
+		fail = func(_ context.Context, _ []RunwareSuccessResponseBody, err error) (*[]RunwareSuccessResponseBody, error) {
+			return nil, err
+		}
+	}
+	parent := ctx
+	start := time.Now()
+	if g.maxElapsed > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, g.maxElapsed)
+		defer cancel()
+	}
+	var results []RunwareSuccessResponseBody
+	backoff := g.retryBackoff
+	for attempt := 0; ; attempt++ {
+		data, err := send(ctx)
+		if data != nil {
+			results = mergeResults(results, *data)
+		}
+		if err == nil {
+			return &results, nil
+		}
+		var tErr *TransportError
+		if g.maxRetries > 0 && errors.As(err, &tErr) {
+			tErr.Attempt = attempt + 1
+		}
+		if attempt >= g.maxRetries || !isTransient(err) {
+			return fail(ctx, results, err)
+		}
+		wait := backoff
+		backoff *= 2
+		// A 429 tells us exactly how long to wait; prefer that over backoff.
+		var rateErr *RateLimitedError
+		if errors.As(err, &rateErr) && rateErr.RetryAfter > 0 {
+			wait = rateErr.RetryAfter
+		}
+		if sleepContext(ctx, wait) != nil {
+			if g.maxElapsed > 0 && parent.Err() == nil {
+				err = fmt.Errorf("retry budget exhausted after %d attempts in %s: %w",
+					attempt+1, time.Since(start).Round(time.Millisecond), err)
+			}
+			return fail(ctx, results, err)
+		}
+	}
+}
+
+// sleepContext waits for d or until ctx is done. It returns immediately when
+// ctx's deadline would expire before the wait is over, since the next attempt
+// could not be made in time anyway.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < d {
+		return context.DeadlineExceeded
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// IsRetryable reports whether retrying the call that returned err is likely
+// to help: rate limiting, 5xx responses, network failures and timeouts. It
+// returns false for permanent errors such as invalid parameters or a bad API
+// key, and for calls canceled by the caller. It is meant for callers running
+// their own retry policy instead of WithRetries.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var timeoutErr *TaskTimeoutError
+	if errors.As(err, &timeoutErr) || errors.Is(err, ErrCircuitOpen) {
+		return true
+	}
+	return isTransient(err)
+}
+
+// isTransient reports whether a failed attempt is worth retrying.
+func isTransient(err error) bool {
+	var apiErr *RunwareAPIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	return isServerFailure(err)
+}
+
+// isServerFailure reports whether err means the API itself is unavailable
+// (timeouts, connection failures and 5xx), as opposed to rejecting or
+// throttling us.
+func isServerFailure(err error) bool {
+	var apiErr *RunwareAPIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode >= 500
+	}
+	return errors.Is(err, ErrTimeout) || errors.Is(err, ErrConnection)
+}
+
+// mergeResults appends next to results, skipping entries already present.
+func mergeResults(results, next []RunwareSuccessResponseBody) []RunwareSuccessResponseBody {
+	return Dedup(append(results, next...))
+}
+
+// Dedup returns results with duplicate images removed, keeping the first
+// occurrence. Results are keyed by ImageUUID, falling back to TaskUUID when
+// the API did not return an image identifier.
+func Dedup(results []RunwareSuccessResponseBody) []RunwareSuccessResponseBody {
+	seen := make(map[string]bool, len(results))
+	deduped := make([]RunwareSuccessResponseBody, 0, len(results))
+	for _, r := range results {
+		key := resultKey(r)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		deduped = append(deduped, r)
+	}
+	return deduped
+}
+
+func resultKey(r RunwareSuccessResponseBody) string {
+	if r.ImageUUID != "" {
+		return r.ImageUUID
+	}
+	return r.TaskUUID
+}
+
+// sendGuarded sends a single attempt through the circuit breaker, if any.
+func sendGuarded(ctx context.Context, g *generateImagesV1Impl, urls []string) (*[]RunwareSuccessResponseBody, error) {
+	if g.breaker == nil {
+		return sendWithKeys(ctx, g, urls)
+	}
+	if err := g.breaker.allow(); err != nil {
+		return nil, err
+	}
+	data, err := sendWithKeys(ctx, g, urls)
+	g.breaker.record(err)
+	return data, err
+}
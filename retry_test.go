@@ -0,0 +1,198 @@
+package runware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// respondInTurn answers the n-th request with the n-th handler, repeating the
+// last one.
+func respondInTurn(handlers ...http.HandlerFunc) http.HandlerFunc {
+	var calls atomic.Int64
+	return func(w http.ResponseWriter, r *http.Request) {
+		n := int(calls.Add(1)) - 1
+		handlers[min(n, len(handlers)-1)](w, r)
+	}
+}
+
+// dropConnection closes the connection without answering, as when a response
+// is lost after the server has processed the request.
+func dropConnection(w http.ResponseWriter, r *http.Request) {
+	conn, _, err := w.(http.Hijacker).Hijack()
+	if err == nil {
+		conn.Close()
+	}
+}
+
+// requestUUIDs returns the taskUUIDs of every request the server received.
+func (api *fakeAPI) requestUUIDs(t *testing.T) [][]string {
+	t.Helper()
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	var all [][]string
+	for _, body := range api.bodies {
+		var tasks []map[string]any
+		if err := json.Unmarshal(body, &tasks); err != nil {
+			t.Fatal(err)
+		}
+		var uuids []string
+		for _, task := range tasks {
+			uuids = append(uuids, task["taskUUID"].(string))
+		}
+		all = append(all, uuids)
+	}
+	return all
+}
+
+func TestRetryKeepsTaskUUIDsAndDedupes(t *testing.T) {
+	failedWithData := func(w http.ResponseWriter, r *http.Request) {
+		var tasks []map[string]any
+		json.NewDecoder(r.Body).Decode(&tasks)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]any{"data": []map[string]any{
+			{"taskType": "imageInference", "taskUUID": tasks[0]["taskUUID"], "imageUUID": "image-" + tasks[0]["taskUUID"].(string)},
+		}})
+	}
+	tests := []struct {
+		name  string
+		first http.HandlerFunc
+	}{
+		{"response lost, retry succeeds", dropConnection},
+		{"both attempts return data", failedWithData},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newFakeAPI(t, respondInTurn(tt.first, echoResults))
+			results, err := api.client(WithRetries(2, 0)).Config([]map[string]any{testTask(nil), testTask(nil)}).GenerateV1()
+			if err != nil {
+				t.Fatal(err)
+			}
+			attempts := api.requestUUIDs(t)
+			if len(attempts) != 2 {
+				t.Fatalf("server got %d requests, want 2", len(attempts))
+			}
+			if !reflect.DeepEqual(attempts[0], attempts[1]) {
+				t.Errorf("taskUUIDs changed between attempts: %v then %v", attempts[0], attempts[1])
+			}
+			if len(*results) != 2 {
+				t.Errorf("got %d results, want one per task without duplicates: %+v", len(*results), *results)
+			}
+		})
+	}
+}
+
+func TestDedup(t *testing.T) {
+	tests := []struct {
+		name    string
+		results []RunwareSuccessResponseBody
+		want    []RunwareSuccessResponseBody
+	}{
+		{"empty", nil, []RunwareSuccessResponseBody{}},
+		{
+			name: "duplicate imageUUIDs keep the first",
+			results: []RunwareSuccessResponseBody{
+				{TaskUUID: "a", ImageUUID: "1", Seed: 1},
+				{TaskUUID: "a", ImageUUID: "2"},
+				{TaskUUID: "a", ImageUUID: "1", Seed: 2},
+			},
+			want: []RunwareSuccessResponseBody{{TaskUUID: "a", ImageUUID: "1", Seed: 1}, {TaskUUID: "a", ImageUUID: "2"}},
+		},
+		{
+			name:    "taskUUID without imageUUID",
+			results: []RunwareSuccessResponseBody{{TaskUUID: "a"}, {TaskUUID: "b"}, {TaskUUID: "a"}},
+			want:    []RunwareSuccessResponseBody{{TaskUUID: "a"}, {TaskUUID: "b"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Dedup(tt.results); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Dedup = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	apiErr := func(status int, code ErrorCode) *RunwareAPIError {
+		err := &RunwareAPIError{StatusCode: status}
+		if code != "" {
+			err.Errors = []RunwareErrorResponseBody{{Code: code}}
+		}
+		return err
+	}
+	decodeErr := func(err error) error {
+		return &TransportError{URL: "https://api.runware.ai/v1", Err: &redactedError{err: &DecodeError{StatusCode: http.StatusOK, Err: err}, apiKey: testAPIKey}}
+	}
+	transport := func(err error) error {
+		return &TransportError{URL: "https://api.runware.ai/v1", Err: &url.Error{Op: "Post", URL: "https://api.runware.ai/v1", Err: err}}
+	}
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"rate limited", &RateLimitedError{Err: apiErr(http.StatusTooManyRequests, "")}, true},
+		{"429", apiErr(http.StatusTooManyRequests, ""), true},
+		{"500", apiErr(http.StatusInternalServerError, ""), true},
+		{"503 wrapped", fmt.Errorf("generating: %w", apiErr(http.StatusServiceUnavailable, "")), true},
+		{"connection reset", transport(syscall.ECONNRESET), true},
+		{"network timeout", transport(context.DeadlineExceeded), true},
+		{"task timeout", &TaskTimeoutError{Err: context.DeadlineExceeded}, true},
+		{"circuit open", ErrCircuitOpen, true},
+		{"400 invalid parameter", apiErr(http.StatusBadRequest, "invalidPositivePrompt"), false},
+		{"401", apiErr(http.StatusUnauthorized, ""), false},
+		{"403", apiErr(http.StatusForbidden, ""), false},
+		{"canceled", transport(context.Canceled), false},
+		{"no tasks", ErrNoTasks, false},
+		// doRequest wraps decode errors in a *TransportError: a truncated
+		// response matches ErrConnection and is retried, malformed JSON is not.
+		{"truncated response", decodeErr(io.ErrUnexpectedEOF), true},
+		{"malformed response", decodeErr(&json.SyntaxError{Offset: 9}), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryable(tt.err); got != tt.want {
+				t.Errorf("IsRetryable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryDecodeErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		wantRetry bool
+	}{
+		{"truncated response", `{"data":[{"taskType":"imageInference",`, true},
+		{"malformed response", `{"data":[nonsense]}`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newFakeAPI(t, respondInTurn(respondJSON(http.StatusOK, tt.body), echoResults))
+			_, err := api.client(WithRetries(1, time.Millisecond)).Config([]map[string]any{testTask(nil)}).GenerateV1()
+			if tt.wantRetry != (err == nil) {
+				t.Fatalf("GenerateV1() = %v, want retried %v", err, tt.wantRetry)
+			}
+			if want := map[bool]int{true: 2, false: 1}[tt.wantRetry]; api.requests() != want {
+				t.Errorf("sent %d requests, want %d", api.requests(), want)
+			}
+			var decodeErr *DecodeError
+			if err != nil && !errors.As(err, &decodeErr) {
+				t.Errorf("GenerateV1() = %v, want a *DecodeError", err)
+			}
+		})
+	}
+}
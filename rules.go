@@ -0,0 +1,57 @@
+package runware
+
+import "fmt"
+
+// taskRule is a cross-field requirement a task must meet.
+type taskRule struct {
+	// message describes the requirement, as shown when it is broken.
+	message string
+	broken  func(option RunwareOptions) bool
+}
+
+var (
+	requireInputImage = taskRule{"inputImage is required", func(o RunwareOptions) bool { return o.InputImage == "" }}
+	forbidPrompt      = taskRule{"prompt is not allowed", func(o RunwareOptions) bool { return o.Prompt != "" || o.NegativePrompt != "" }}
+)
+
+// taskRules lists, per task type, the rules checked by Validate and before
+// sending. Adding a task type to knownTaskTypes should come with its rules
+// here.
+var taskRules = map[TaskType][]taskRule{
+	ImageInference: {
+		{"maskImage requires seedImage", func(o RunwareOptions) bool { return o.MaskImage != "" && o.SeedImage == "" }},
+		{"strength requires seedImage", func(o RunwareOptions) bool { return o.Strength != nil && o.SeedImage == "" }},
+	},
+	ImageUpscale:           {requireInputImage, forbidPrompt},
+	ImageBackgroundRemoval: {requireInputImage, forbidPrompt},
+	PhotoMaker: {
+		{"inputImages is required", func(o RunwareOptions) bool { return len(o.InputImages) == 0 }},
+	},
+}
+
+// checkTaskRules reports every rule of the task's type that it breaks.
+func checkTaskRules(i int, option RunwareOptions) []error {
+	var errs []error
+	for _, rule := range taskRules[option.TaskType] {
+		if rule.broken(option) {
+			errs = append(errs, fmt.Errorf("task %d: %s: %s", i, option.TaskType, rule.message))
+		}
+	}
+	return errs
+}
+
+// checkMaskMargin reports a maskMargin that is not positive, or that is set
+// without the maskImage it extends, whatever the task type.
+func checkMaskMargin(i int, option RunwareOptions) []error {
+	if option.MaskMargin == nil {
+		return nil
+	}
+	var errs []error
+	if *option.MaskMargin <= 0 {
+		errs = append(errs, fmt.Errorf("task %d: maskMargin must be positive, got %d", i, *option.MaskMargin))
+	}
+	if option.MaskImage == "" {
+		errs = append(errs, fmt.Errorf("task %d: maskMargin requires maskImage", i))
+	}
+	return errs
+}
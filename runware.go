@@ -2,11 +2,18 @@ package runware
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"math"
+	"mime"
 	"net/http"
-	"slices"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -16,6 +23,16 @@ type OutputType string
 type OutputFormat string
 type Definition uint16
 
+// Task types other than ImageInference, which work from input images.
+const (
+	// ImageUpscale enlarges inputImage.
+	ImageUpscale TaskType = "imageUpscale"
+	// ImageBackgroundRemoval removes the background of inputImage.
+	ImageBackgroundRemoval TaskType = "imageBackgroundRemoval"
+	// PhotoMaker generates images of the subject shown in inputImages.
+	PhotoMaker TaskType = "photoMaker"
+)
+
 const (
 	ImageInference TaskType     = "imageInference"
 	Base64Data     OutputType   = "base64Data"
@@ -48,19 +65,52 @@ const (
 	HD_Landscape16_9Width  Definition = 1728
 )
 
+// RunwareOptions is a single task as sent to the API. Optional fields are
+// left out of the payload when unset: zero values for strings and numbers,
+// nil for pointers, so that an explicit false can be told apart from an
+// unset flag.
 type RunwareOptions struct {
-	TaskType        TaskType     `json:"taskType"`
-	TaskUUID        string       `json:"taskUUID"`
-	Prompt          string       `json:"prompt"`
-	Model           string       `json:"model"`
-	UploadEndpoint  string       `json:"uploadEndpoint"`
-	OutputType      OutputType   `json:"outputType"`
-	OutputFormat    OutputFormat `json:"outputFormat"`
-	Width           Definition   `json:"width"`
-	Height          Definition   `json:"height"`
-	NumberOfResults uint8        `json:"numberOfResults"`
-	CheckNSFW       bool         `json:"checkNSFW"`
-	IncludeCost     bool         `json:"includeCost"`
+	TaskType        TaskType        `json:"taskType"`
+	TaskUUID        string          `json:"taskUUID"`
+	Prompt          string          `json:"positivePrompt,omitempty"`
+	NegativePrompt  string          `json:"negativePrompt,omitempty"`
+	PromptWeighting PromptWeighting `json:"promptWeighting,omitempty"`
+	Model           string          `json:"model,omitempty"`
+	UploadEndpoint  string          `json:"uploadEndpoint,omitempty"`
+	OutputType      OutputType      `json:"outputType,omitempty"`
+	OutputFormat    OutputFormat    `json:"outputFormat,omitempty"`
+	OutputQuality   int             `json:"outputQuality,omitempty"`
+	Lossless        bool            `json:"lossless,omitempty"`
+	Width           Definition      `json:"width,omitempty"`
+	Height          Definition      `json:"height,omitempty"`
+	// NumberOfResults is omitted when zero, so the API default of one
+	// result applies unless at least one is asked for explicitly.
+	NumberOfResults uint8    `json:"numberOfResults,omitempty"`
+	CheckNSFW       *bool    `json:"checkNSFW,omitempty"`
+	IncludeCost     *bool    `json:"includeCost,omitempty"`
+	SeedImage       string   `json:"seedImage,omitempty"`
+	InputImage      string   `json:"inputImage,omitempty"`
+	InputImages     []string `json:"inputImages,omitempty"`
+	MaskImage       string   `json:"maskImage,omitempty"`
+	MaskMargin      *int     `json:"maskMargin,omitempty"`
+	Style           string   `json:"style,omitempty"`
+	Strength        *float64 `json:"strength,omitempty"`
+	Steps           int      `json:"steps,omitempty"`
+	CFGScale        *float64 `json:"CFGScale,omitempty"`
+	ClipSkip        *int     `json:"clipSkip,omitempty"`
+	Seed            *int64   `json:"seed,omitempty"`
+	// VAETiling decodes the image in tiles, which avoids running out of
+	// memory and the seams or color shifts that come with it on large
+	// outputs, typically above 1536 pixels on either side. It is omitted
+	// when false.
+	VAETiling bool `json:"vaeTiling,omitempty"`
+	// Architecture is only used by the client and never sent.
+	Architecture Architecture `json:"-"`
+	// Raw holds extra fields sent with this task only, for API parameters
+	// without a field of their own. A typed field that is set is never
+	// replaced by a raw field of the same JSON name; fields given to
+	// WithExtraFields are merged last and win over both.
+	Raw map[string]any `json:"-"`
 }
 
 type RunwareSuccessResponseBody struct {
@@ -70,17 +120,29 @@ type RunwareSuccessResponseBody struct {
 	ImageUrl        string  `json:"imageUrl"`
 	ImageBase64Data string  `json:"imageBase64Data"`
 	ImageDataURI    string  `json:"imageDataURI"`
-	Seed            int     `json:"seed"`
+	Seed            int64   `json:"seed"`
 	Cost            float64 `json:"cost"`
 	NSFWContent     bool    `json:"nsfwContent"`
+	// Cached reports whether the image was served from Runware's cache. It is
+	// false when the API does not include the field.
+	Cached bool `json:"cached"`
+	// Width and Height are the size of the generated image as reported by
+	// the API, which may differ from the requested size after rounding. They
+	// are zero when the API does not include them.
+	Width  Definition `json:"width"`
+	Height Definition `json:"height"`
+	// Extra holds response fields this version of the SDK does not know,
+	// keyed by their JSON name, so newer API fields can be read before they
+	// get a field of their own. It is nil when there were none.
+	Extra map[string]json.RawMessage `json:"-"`
 }
 
 type RunwareErrorResponseBody struct {
-	Code      string `json:"code"`
-	Message   string `json:"message"`
-	Parameter string `json:"parameter"`
-	Type      string `json:"type"`
-	TaskType  string `json:"taskType"`
+	Code      ErrorCode `json:"code"`
+	Message   string    `json:"message"`
+	Parameter string    `json:"parameter"`
+	Type      string    `json:"type"`
+	TaskType  string    `json:"taskType"`
 }
 
 type RunwareResponseBody struct {
@@ -90,159 +152,659 @@ type RunwareResponseBody struct {
 
 // Interface definition
 type GenerateImagesV1 interface {
+	// Config replaces any previous configuration with one task per map. Keys
+	// whose value is nil, or a typed nil such as a nil *string, are treated
+	// as unset; a nil map is reported as an error by GenerateV1.
 	Config(data []map[string]any) GenerateImagesV1
+	// Override applies values, using the same keys as Config, to every
+	// configured task. taskUUID cannot be overridden.
+	Override(values map[string]any) GenerateImagesV1
+	// SeedImageReader reads an image from r and sets it as the seed image of
+	// every configured task. It must be called after Config.
+	SeedImageReader(r io.Reader) GenerateImagesV1
+	// ConfigFromFile configures the client from a JSON or YAML file.
+	ConfigFromFile(path string) (GenerateImagesV1, error)
+	// Validate checks the configured tasks without contacting the API.
+	Validate() error
+	// Clone returns an independent copy of the client and its configured
+	// tasks.
+	Clone() GenerateImagesV1
 	GenerateV1() (*[]RunwareSuccessResponseBody, error)
+	// GenerateV1Ordered runs GenerateV1 and returns the results in the order
+	// of the configured tasks.
+	GenerateV1Ordered() (*[]RunwareSuccessResponseBody, error)
+	// GenerateV1Context is GenerateV1 bound to ctx, which cancels the request
+	// and any retry waits. If ctx's deadline expires, results received so far
+	// are returned together with a *TaskTimeoutError.
+	GenerateV1Context(ctx context.Context) (*[]RunwareSuccessResponseBody, error)
+	// GenerateV1Async runs GenerateV1 in the background and delivers its
+	// outcome on the returned channel.
+	GenerateV1Async() <-chan Result
+	// GenerateAndSave runs GenerateV1 and writes every result into dir,
+	// downloading URL results, and returns the paths of the written files.
+	GenerateAndSave(dir string) ([]string, error)
+	// GenerateToSink runs the configured tasks, streaming base64 image data
+	// into the writers returned by sink rather than into the results.
+	GenerateToSink(ctx context.Context, sink ImageSink) (*[]RunwareSuccessResponseBody, error)
+	// Variations generates n variations of a previous result, identified by
+	// its imageUUID, using image-to-image on top of the first configured task.
+	Variations(baseUUID string, n int) (*[]RunwareSuccessResponseBody, error)
+	// LastRateLimit returns the rate-limit headers of the most recent
+	// response, successful or not, or nil if they were not present.
+	LastRateLimit() *RateLimitInfo
+	// LastResponseMeta returns the status and headers of interest of the most
+	// recent response, or nil before the first response.
+	LastResponseMeta() *ResponseMeta
+	// Ping checks connectivity and the API key without generating an image.
+	Ping(ctx context.Context) error
+	// Warmup opens connections to the API ahead of the first call.
+	Warmup(ctx context.Context) error
+	// HTTPClient returns the HTTP client requests are sent with, e.g. to
+	// pass to AsPNG.
+	HTTPClient() *http.Client
+	// Progress returns how far a task has got, from 0 to 1.
+	Progress(taskUUID string) (float64, error)
+	// IsOpen reports whether the circuit breaker is currently rejecting
+	// requests. It is always false when no breaker is configured.
+	IsOpen() bool
+	// LastAdjustments returns the input images WithAutoResize resized for the
+	// most recent call.
+	LastAdjustments() []ImageAdjustment
+	// LastRawResponse returns the body of the most recent response as read,
+	// when WithCaptureRawResponse is enabled.
+	LastRawResponse() []byte
+	// LastShortfalls returns the tasks of the most recent call that received
+	// fewer results than they asked for.
+	LastShortfalls() []ResultShortfall
+	// Enqueue buffers a task to be sent by the next Flush.
+	Enqueue(task RunwareOptions) error
+	// Flush sends every task buffered by Enqueue in batches and returns the
+	// results keyed by taskUUID.
+	Flush(ctx context.Context) (map[string][]RunwareSuccessResponseBody, error)
+	// Close cancels calls in flight, makes later calls fail with ErrClosed
+	// and closes idle connections.
+	Close() error
 }
 
 // Struct implementing the interface
 type generateImagesV1Impl struct {
-	apiKey        string
-	options       []RunwareOptions
-	omittedFields []string
+	apiKey           string
+	apiKeys          []string
+	keyIndex         int
+	httpClient       *http.Client
+	options          []RunwareOptions
+	maxRetries       int
+	retryBackoff     time.Duration
+	breaker          *circuitBreaker
+	validateAspect   bool
+	filenameTemplate string
+	hedgeDelay       time.Duration
+	maxElapsed       time.Duration
+	metrics          Metrics
+	explicitUUIDs    bool
+	configErr        error
+	// earlyConfigErr holds a misuse before the first Config, such as
+	// SeedImageReader without tasks, for that Config to report.
+	earlyConfigErr       error
+	requestSigner        func(*http.Request) error
+	strictConfig         bool
+	streamThreshold      int
+	roundDimensions      bool
+	truncatePrompts      bool
+	validatePromptSyntax bool
+	maxResponseBytes     int64
+	rateLimitObserver    func(RateLimitInfo)
+	imageSink            ImageSink
+	endpoints            []string
+	endpointCooldown     time.Duration
+	auditLog             *auditLog
+	authHeader           string
+	authFormat           string
+	queueCapacity        int
+	batchSize            int
+	extraFields          map[string]any
+	optionErr            error
+	autoResize           bool
+	apiVersion           string
+	costBudget           float64
+	strictDecoding       bool
+	clampSteps           bool
+	captureRaw           bool
+	allowUnknownTasks    bool
+	defaultOutputType    OutputType
+	defaultOutputFormat  OutputFormat
+	validateInputImages  bool
+
+	// state is shared by copies of the client made for derived requests.
+	state *clientState
 }
 
-func NewGenerateImagesV1(apiKey string) GenerateImagesV1 {
-	return &generateImagesV1Impl{
-		apiKey:        apiKey,
-		omittedFields: []string{},
+// clientState holds what the client records from responses. Unlike the rest
+// of the client it is mutated after construction, so it is guarded by mu.
+type clientState struct {
+	mu       sync.Mutex
+	lastMeta *ResponseMeta
+	// lastAdjustments is only set when WithAutoResize is enabled.
+	lastAdjustments []ImageAdjustment
+	// spent is the total reported cost, tracked when WithCostBudget is set.
+	spent float64
+	// lastRaw is only set when WithCaptureRawResponse is enabled.
+	lastRaw []byte
+	// endpointDownUntil maps the URL of an endpoint that failed to the time
+	// it may be tried again. It is only used with several WithEndpoints.
+	endpointDownUntil map[string]time.Time
+	// lastShortfalls lists the tasks of the last call that got too few
+	// results.
+	lastShortfalls []ResultShortfall
+	// queue holds the tasks buffered by Enqueue.
+	queue []queuedTask
+	// closed is set by Close, which also closes done.
+	closed bool
+	done   chan struct{}
+}
+
+func NewGenerateImagesV1(apiKey string, opts ...Option) GenerateImagesV1 {
+	g := &generateImagesV1Impl{
+		apiKey: apiKey,
+		httpClient: &http.Client{
+			Transport: newTransport(DefaultMaxIdleConns, DefaultMaxIdleConnsPerHost, DefaultIdleConnTimeout),
+		},
+		metrics:          noopMetrics{},
+		streamThreshold:  DefaultStreamThreshold,
+		maxResponseBytes: DefaultMaxResponseBytes,
+		state:            &clientState{},
+		apiVersion:       DefaultAPIVersion,
+		endpointCooldown: DefaultEndpointCooldown,
+		authHeader:       "Authorization",
+		authFormat:       "Bearer %s",
+		queueCapacity:    DefaultQueueCapacity,
+		batchSize:        DefaultBatchSize,
 	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
+}
+
+// NewSingleImageRequest returns a client configured for one imageInference
+// task producing a single 1024x1024 PNG. Use Override to adjust it.
+func NewSingleImageRequest(apiKey, prompt, model string, opts ...Option) GenerateImagesV1 {
+	return NewGenerateImagesV1(apiKey, opts...).Config([]map[string]any{
+		{
+			"taskType":     ImageInference,
+			"prompt":       prompt,
+			"model":        model,
+			"width":        HD_Width,
+			"height":       HD_Height,
+			"results":      uint8(1),
+			"outputFormat": PNG,
+		},
+	})
 }
 
 func (g *generateImagesV1Impl) Config(options []map[string]any) GenerateImagesV1 {
-	g.options = make([]RunwareOptions, len(options))
+	g.reset(make([]RunwareOptions, len(options)))
 	for i, data := range options {
-		if data["taskType"] != nil {
-			g.options[i].TaskType = data["taskType"].(TaskType)
+		if data == nil {
+			g.configErr = errors.Join(g.configErr, fmt.Errorf("task %d: nil map, expected task parameters", i))
 		}
+		data = withoutNils(data)
 		if data["taskUUID"] != nil {
-			g.options[i].TaskUUID = data["taskUUID"].(string)
+			taskUUID, err := toString(data["taskUUID"])
+			g.addConfigErr(i, "taskUUID", err)
+			g.options[i].TaskUUID = taskUUID
 		} else {
 			g.options[i].TaskUUID = uuid.New().String()
+			g.explicitUUIDs = false
 		}
-		if data["prompt"] != nil {
-			g.options[i].Prompt = data["prompt"].(string)
-		}
-		if data["width"] != nil {
-			g.options[i].Width = data["width"].(Definition)
-		}
-		if data["height"] != nil {
-			g.options[i].Height = data["height"].(Definition)
-		}
-		if data["model"] != nil {
-			g.options[i].Model = data["model"].(string)
-		}
-		if data["results"] != nil {
-			g.options[i].NumberOfResults = data["results"].(uint8)
-		}
-		if data["uploadEndpoint"] != nil {
-			g.options[i].UploadEndpoint = data["uploadEndpoint"].(string)
-		}
-		if data["checkNSFW"] != nil {
-			g.options[i].CheckNSFW = data["checkNSFW"].(bool)
-		} else {
-			g.omittedFields = append(g.omittedFields, "checkNSFW")
-		}
-		if data["includeCost"] != nil {
-			g.options[i].IncludeCost = data["includeCost"].(bool)
-		} else {
-			g.omittedFields = append(g.omittedFields, "includeCost")
+		g.applyConfig(i, data)
+	}
+	return g
+}
+
+func (g *generateImagesV1Impl) Override(values map[string]any) GenerateImagesV1 {
+	values = withoutNils(values)
+	for i := range g.options {
+		g.applyConfig(i, values)
+	}
+	return g
+}
+
+// reset replaces the configured tasks and clears all state derived from a
+// previous configuration, so that a client can be configured again.
+func (g *generateImagesV1Impl) reset(options []RunwareOptions) {
+	g.options = options
+	g.configErr = g.earlyConfigErr
+	g.earlyConfigErr = nil
+	g.explicitUUIDs = true
+}
+
+// applyConfig sets the fields of the i-th task for every key present in data.
+// taskUUID is handled by Config since it must stay unique per task.
+func (g *generateImagesV1Impl) applyConfig(i int, data map[string]any) {
+	if data["taskType"] != nil {
+		taskType, err := toTaskType(data["taskType"])
+		if err == nil && g.strictConfig && !taskType.IsValid() {
+			err = fmt.Errorf("unknown value %q, expected one of %v", taskType, knownTaskTypes)
 		}
-		if data["outputType"] != nil {
-			g.options[i].OutputType = data["outputType"].(OutputType)
+		g.addConfigErr(i, "taskType", err)
+		g.options[i].TaskType = taskType
+	}
+	if data["prompt"] != nil {
+		prompt, err := toString(data["prompt"])
+		g.addConfigErr(i, "prompt", err)
+		g.options[i].Prompt = prompt
+	}
+	if data["negativePrompt"] != nil {
+		negativePrompt, err := toString(data["negativePrompt"])
+		g.addConfigErr(i, "negativePrompt", err)
+		g.options[i].NegativePrompt = negativePrompt
+	}
+	if data["promptWeighting"] != nil {
+		promptWeighting, err := toPromptWeighting(data["promptWeighting"])
+		g.addConfigErr(i, "promptWeighting", err)
+		g.options[i].PromptWeighting = promptWeighting
+	}
+	if data["width"] != nil {
+		width, err := getDimensionValue(data["width"])
+		g.addConfigErr(i, "width", err)
+		g.options[i].Width = width
+	}
+	if data["height"] != nil {
+		height, err := getDimensionValue(data["height"])
+		g.addConfigErr(i, "height", err)
+		g.options[i].Height = height
+	}
+	if data["model"] != nil {
+		model, err := toModel(data["model"])
+		g.addConfigErr(i, "model", err)
+		g.options[i].Model = model
+	}
+	if data["results"] != nil {
+		results, err := toIntInRange(data["results"], 0, math.MaxUint8)
+		g.addConfigErr(i, "results", err)
+		g.options[i].NumberOfResults = uint8(results)
+	}
+	if data["uploadEndpoint"] != nil {
+		uploadEndpoint, err := toString(data["uploadEndpoint"])
+		g.addConfigErr(i, "uploadEndpoint", err)
+		g.options[i].UploadEndpoint = uploadEndpoint
+	}
+	if data["checkNSFW"] != nil {
+		checkNSFW, err := toBool(data["checkNSFW"])
+		g.addConfigErr(i, "checkNSFW", err)
+		g.options[i].CheckNSFW = &checkNSFW
+	}
+	if data["includeCost"] != nil {
+		includeCost, err := toBool(data["includeCost"])
+		g.addConfigErr(i, "includeCost", err)
+		g.options[i].IncludeCost = &includeCost
+	}
+	if data["outputType"] != nil {
+		outputType, err := toOutputType(data["outputType"])
+		if err == nil && g.strictConfig && !outputType.IsValid() {
+			err = fmt.Errorf("unknown value %q, expected one of %v", outputType, knownOutputTypes)
 		}
-		if data["outputFormat"] != nil {
-			g.options[i].OutputFormat = data["outputFormat"].(OutputFormat)
+		g.addConfigErr(i, "outputType", err)
+		g.options[i].OutputType = outputType
+	}
+	if data["outputFormat"] != nil {
+		outputFormat, err := toOutputFormat(data["outputFormat"])
+		if err == nil && g.strictConfig && !outputFormat.IsValid() {
+			err = fmt.Errorf("unknown value %q, expected one of %v", outputFormat, knownOutputFormats)
 		}
+		g.addConfigErr(i, "outputFormat", err)
+		g.options[i].OutputFormat = outputFormat
+	}
+	if data["outputQuality"] != nil {
+		outputQuality, err := toIntInRange(data["outputQuality"], 0, math.MaxInt32)
+		g.addConfigErr(i, "outputQuality", err)
+		g.options[i].OutputQuality = int(outputQuality)
+	}
+	if data["seedImage"] != nil {
+		seedImage, err := toString(data["seedImage"])
+		g.addConfigErr(i, "seedImage", err)
+		g.options[i].SeedImage = seedImage
+	}
+	if data["inputImage"] != nil {
+		inputImage, err := toString(data["inputImage"])
+		g.addConfigErr(i, "inputImage", err)
+		g.options[i].InputImage = inputImage
+	}
+	if data["inputImages"] != nil {
+		inputImages, err := toStringSlice(data["inputImages"])
+		g.addConfigErr(i, "inputImages", err)
+		g.options[i].InputImages = inputImages
+	}
+	if data["maskImage"] != nil {
+		maskImage, err := toString(data["maskImage"])
+		g.addConfigErr(i, "maskImage", err)
+		g.options[i].MaskImage = maskImage
+	}
+	if data["style"] != nil {
+		style, err := toString(data["style"])
+		g.addConfigErr(i, "style", err)
+		g.options[i].Style = style
+	}
+	if data["steps"] != nil {
+		steps, err := toIntInRange(data["steps"], 0, math.MaxInt32)
+		g.addConfigErr(i, "steps", err)
+		g.options[i].Steps = int(steps)
+	}
+	if data["CFGScale"] != nil {
+		cfgScale, err := toFloat64(data["CFGScale"])
+		g.addConfigErr(i, "CFGScale", err)
+		g.options[i].CFGScale = &cfgScale
+	}
+	if data["clipSkip"] != nil {
+		clipSkip, err := toIntInRange(data["clipSkip"], math.MinInt32, math.MaxInt32)
+		g.addConfigErr(i, "clipSkip", err)
+		skip := int(clipSkip)
+		g.options[i].ClipSkip = &skip
+	}
+	if data["architecture"] != nil {
+		architecture, err := toArchitecture(data["architecture"])
+		g.addConfigErr(i, "architecture", err)
+		g.options[i].Architecture = architecture
+	}
+	if data["lossless"] != nil {
+		lossless, err := toBool(data["lossless"])
+		g.addConfigErr(i, "lossless", err)
+		g.options[i].Lossless = lossless
+	}
+	if data["vaeTiling"] != nil {
+		vaeTiling, err := toBool(data["vaeTiling"])
+		g.addConfigErr(i, "vaeTiling", err)
+		g.options[i].VAETiling = vaeTiling
+	}
+	if data["seed"] != nil {
+		seed, err := toSeed(data["seed"])
+		g.addConfigErr(i, "seed", err)
+		g.options[i].Seed = &seed
+	}
+	if data["raw"] != nil {
+		raw, err := toRawFields(data["raw"])
+		g.addConfigErr(i, "raw", err)
+		g.options[i].Raw = raw
+	}
+	if data["strength"] != nil {
+		strength, err := toFloat64(data["strength"])
+		g.addConfigErr(i, "strength", err)
+		g.options[i].Strength = &strength
+	}
+	if data["maskMargin"] != nil {
+		maskMargin, err := toIntInRange(data["maskMargin"], math.MinInt32, math.MaxInt32)
+		g.addConfigErr(i, "maskMargin", err)
+		margin := int(maskMargin)
+		g.options[i].MaskMargin = &margin
+	}
+}
+
+// addConfigErr records a problem with key in the i-th task passed to Config.
+// Config cannot return errors, so they are reported by GenerateV1.
+func (g *generateImagesV1Impl) addConfigErr(i int, key string, err error) {
+	if err != nil {
+		g.configErr = errors.Join(g.configErr, fmt.Errorf("task %d: invalid %s: %w", i, key, err))
 	}
-	return g
 }
 
 func (g *generateImagesV1Impl) GenerateV1() (*[]RunwareSuccessResponseBody, error) {
-	var v1Domain string = "https://api.runware.ai/v1"
-	return sendRequest(g, v1Domain)
+	return g.GenerateV1Context(context.Background())
 }
 
-func buildClient(g *generateImagesV1Impl, url string) (*http.Client, *http.Request, error) {
-	var payload []map[string]any = make([]map[string]any, 0)
-	for _, request := range g.options {
-		width, err := getDimensionValue(request.Width)
-		if err != nil {
-			return nil, nil, fmt.Errorf("invalid width: %w", err)
-		}
-		request.Width = Definition(width)
-		height, err := getDimensionValue(request.Height)
+func (g *generateImagesV1Impl) GenerateV1Context(ctx context.Context) (*[]RunwareSuccessResponseBody, error) {
+	ctx, release, err := g.bindClose(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	if err := g.validate(false); err != nil {
+		return nil, err
+	}
+	if g.autoResize {
+		options, adjustments, err := autoResize(g.options)
 		if err != nil {
-			return nil, nil, fmt.Errorf("invalid height: %w", err)
+			return nil, err
 		}
-		request.Height = Definition(height)
-		payload = append(payload, skipEmptyOrNil(map[string]any{
-			"taskType":        request.TaskType,
-			"taskUUID":        request.TaskUUID,
-			"positivePrompt":  request.Prompt,
-			"width":           request.Width,
-			"height":          request.Height,
-			"model":           request.Model,
-			"numberOfResults": request.NumberOfResults,
-			"uploadEndpoint":  request.UploadEndpoint,
-			"checkNSFW":       request.CheckNSFW,
-			"includeCost":     request.IncludeCost,
-			"outputType":      request.OutputType,
-			"outputFormat":    request.OutputFormat,
-		}, g.omittedFields))
-	}
-
-	jsonData, err := json.Marshal(payload)
+		g.state.mu.Lock()
+		g.state.lastAdjustments = adjustments
+		g.state.mu.Unlock()
+		// Send the resized images without changing the configured tasks.
+		resized := *g
+		resized.options = options
+		g = &resized
+	}
+	if err := g.checkBudget(); err != nil {
+		return nil, err
+	}
+	ctx = ensureCorrelationID(ctx)
+	data, err := sendWithRetry(ctx, g, g.endpointURLs())
+	g.spend(data)
+	var received []RunwareSuccessResponseBody
+	if data != nil {
+		received = *data
+	}
+	g.state.mu.Lock()
+	g.state.lastShortfalls = resultShortfalls(g.options, received)
+	g.state.mu.Unlock()
+	return data, closedErr(ctx, err)
+}
+
+func (g *generateImagesV1Impl) IsOpen() bool {
+	return g.breaker != nil && g.breaker.isOpen()
+}
+
+func buildClient(ctx context.Context, g *generateImagesV1Impl, url string) (*http.Client, *http.Request, error) {
+	payload := make([]RunwareOptions, 0, len(g.options))
+	for i, request := range g.options {
+		request = g.withDefaultOutput(request)
+		request.Width, request.Height = g.dimensions(request)
+		request.Prompt = g.prompt(i, "prompt", request.Prompt)
+		request.NegativePrompt = g.prompt(i, "negativePrompt", request.NegativePrompt)
+		request.Steps = g.steps(i, request)
+		payload = append(payload, request)
+	}
+
+	client := g.httpClient
+	body, err := encodePayload(g, payload)
 	if err != nil {
 		return nil, nil, err
 	}
-	client := &http.Client{}
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	req, err := newAPIRequest(ctx, g, url, body)
 	if err != nil {
+		closeBody(body)
 		return nil, nil, err
 	}
-	req.Header.Add("Content-Type", "application/json")
-	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", g.apiKey))
 	return client, req, nil
 }
 
-func sendRequest(g *generateImagesV1Impl, url string) (*[]RunwareSuccessResponseBody, error) {
-	client, req, err := buildClient(g, url)
+// newAPIRequest returns a POST request of body to url carrying the client's
+// authentication, correlation ID and signature.
+func newAPIRequest(ctx context.Context, g *generateImagesV1Impl, url string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", url, body)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := client.Do(req)
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set(g.authHeader, fmt.Sprintf(g.authFormat, g.apiKey))
+	if id, ok := CorrelationID(ctx); ok {
+		req.Header.Set(CorrelationIDHeader, id)
+	}
+	if g.requestSigner != nil {
+		if err := g.requestSigner(req); err != nil {
+			return nil, fmt.Errorf("failed to sign request: %w", err)
+		}
+	}
+	return req, nil
+}
+
+func sendRequest(ctx context.Context, g *generateImagesV1Impl, url string) (*[]RunwareSuccessResponseBody, error) {
+	client, req, err := buildClient(ctx, g, url)
 	if err != nil {
 		return nil, err
 	}
+	g.metrics.IncRequests()
+	start := time.Now()
+	data, err := doRequest(g, client, req)
+	latency := time.Since(start)
+	g.metrics.ObserveLatency(latency)
+	g.audit(ctx, start, latency, data, err)
+	if err != nil {
+		g.metrics.IncFailures()
+	}
+	if data != nil {
+		for _, image := range *data {
+			g.metrics.AddCost(image.Cost)
+		}
+	}
+	return data, err
+}
+
+func doRequest(g *generateImagesV1Impl, client *http.Client, req *http.Request) (*[]RunwareSuccessResponseBody, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, newTransportError(g, req, err)
+	}
 	defer resp.Body.Close()
-	var response RunwareResponseBody
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return nil, err
+	meta := newResponseMeta(resp)
+	meta.KeyIndex = g.keyIndex
+	meta.Endpoint = req.URL.String()
+	g.state.mu.Lock()
+	g.state.lastMeta = meta
+	g.state.mu.Unlock()
+	if meta.RateLimit != nil && g.rateLimitObserver != nil {
+		g.rateLimitObserver(*meta.RateLimit)
+	}
+	if g.maxResponseBytes > 0 {
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{&maxBytesReader{r: resp.Body, limit: g.maxResponseBytes}, resp.Body}
 	}
-	if resp.StatusCode >= 400 {
-		log.Printf("request failed with status %d", resp.StatusCode)
-		jsonDataErrResponse, err := json.MarshalIndent(response, "", "  ")
-		if err == nil {
-			return nil, fmt.Errorf("%s", jsonDataErrResponse)
+	if g.captureRaw {
+		raw := &bytes.Buffer{}
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.TeeReader(resp.Body, raw), resp.Body}
+		defer func() {
+			g.state.mu.Lock()
+			g.state.lastRaw = raw.Bytes()
+			g.state.mu.Unlock()
+		}()
+	}
+	failed := resp.StatusCode < 200 || resp.StatusCode >= 300
+	if !isJSONContentType(resp.Header.Get("Content-Type")) {
+		// Typically an HTML error page from a load balancer or CDN. A failed
+		// status still says what went wrong, so it is reported like any other.
+		if failed {
+			head, _ := io.ReadAll(io.LimitReader(resp.Body, maxBodySnippet))
+			return nil, newStatusError(g, req, resp, meta, nil, redactKey(string(head), g.apiKey))
 		}
+		return nil, newUnexpectedResponseError(resp, g.apiKey)
+	}
+	snippet := &snippetReader{r: resp.Body}
+	var body io.Reader = snippet
+	if g.imageSink != nil {
+		body = newImageStreamReader(body, g.imageSink)
+	}
+	var response RunwareResponseBody
+	decodeErr := json.NewDecoder(body).Decode(&response)
+	// A failed status is an error whether or not its body could be decoded.
+	if failed {
+		// Any data that made it into a failed response is handed back so the
+		// retry layer can merge it with later attempts.
+		return &response.Data, newStatusError(g, req, resp, meta, response.Errors, "")
+	}
+	if errors.Is(decodeErr, ErrResponseTooLarge) {
+		return nil, decodeErr
+	}
+	if decodeErr != nil {
+		return nil, newTransportError(g, req, &DecodeError{StatusCode: resp.StatusCode, Body: redactKey(string(snippet.head), g.apiKey), Err: decodeErr})
+	}
+	if g.strictDecoding {
+		warnUnknownFields(response.Data)
+	}
+	if len(response.Data) == 0 {
+		correlationID, _ := CorrelationID(req.Context())
+		return &response.Data, &NoResultsError{Errors: redactErrors(response.Errors, g.apiKey), Meta: meta, CorrelationID: correlationID}
 	}
 	return &response.Data, nil
 }
 
-func getDimensionValue(dim any) (int16, error) {
-	switch v := dim.(type) {
-	case Definition:
-		return int16(v), nil
-	default:
-		return 0, fmt.Errorf("invalid dimension type, must be Definition or Definition")
+// newStatusError returns the error for a response with a failed status: a
+// *RateLimitedError for 429 and a *RunwareAPIError otherwise. body is the
+// start of a body that was not JSON.
+func newStatusError(g *generateImagesV1Impl, req *http.Request, resp *http.Response, meta *ResponseMeta, errs []RunwareErrorResponseBody, body string) error {
+	correlationID, _ := CorrelationID(req.Context())
+	log.Printf("request %s failed with status %d", correlationID, resp.StatusCode)
+	apiErr := &RunwareAPIError{StatusCode: resp.StatusCode, Errors: redactErrors(errs, g.apiKey), Body: body, Meta: meta, CorrelationID: correlationID}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		retryAfter, _ := parseRetryAfter(resp.Header.Get("Retry-After"))
+		return &RateLimitedError{RetryAfter: retryAfter, Err: apiErr}
 	}
+	return apiErr
 }
 
-func skipEmptyOrNil(option map[string]any, omittedFields []string) map[string]any {
-	for key, value := range option {
-		if value == "" || value == nil || slices.Contains(omittedFields, key) {
-			delete(option, key)
+// encodePayload returns the request body for payload. Payloads carrying
+// images larger than the streaming threshold are written on the fly through a
+// pipe by writePayload instead of being buffered, so a large image is not held
+// in memory a second time as JSON. Streaming is disabled when a request signer
+// is configured, since the signer needs the complete body.
+func encodePayload(g *generateImagesV1Impl, options []RunwareOptions) (io.Reader, error) {
+	if g.streamThreshold <= 0 || g.requestSigner != nil || imagePayloadSize(options) < g.streamThreshold {
+		jsonData, err := json.Marshal(g.withExtraFields(options))
+		if err != nil {
+			return nil, err
 		}
+		return bytes.NewReader(jsonData), nil
+	}
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(writePayload(pw, g, options))
+	}()
+	return pr, nil
+}
+
+// closeBody stops a streaming encoder whose body will never be sent.
+func closeBody(body io.Reader) {
+	if closer, ok := body.(io.Closer); ok {
+		closer.Close()
+	}
+}
+
+// imagePayloadSize is the number of bytes of image data in options.
+func imagePayloadSize(options []RunwareOptions) int {
+	size := 0
+	for _, option := range options {
+		size += len(option.SeedImage) + len(option.MaskImage) + len(option.InputImage)
+		for _, image := range option.InputImages {
+			size += len(image)
+		}
+	}
+	return size
+}
+
+// isJSONContentType reports whether a Content-Type header denotes JSON. A
+// missing header is given the benefit of the doubt.
+func isJSONContentType(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// getDimensionValue converts a width or height given as Definition, any
+// integer type, or a whole-number float to a Definition.
+func getDimensionValue(dim any) (Definition, error) {
+	value, err := toIntInRange(dim, 0, math.MaxUint16)
+	if err != nil {
+		return 0, fmt.Errorf("invalid dimension %v (%T): %w", dim, dim, err)
 	}
-	return option
+	return Definition(value), nil
 }
@@ -0,0 +1,270 @@
+package runware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeAPI is an httptest server standing in for the Runware API. It records
+// the body of every request and answers with respond.
+type fakeAPI struct {
+	*httptest.Server
+	mu      sync.Mutex
+	bodies  [][]byte
+	headers []http.Header
+}
+
+func newFakeAPI(t *testing.T, respond http.HandlerFunc) *fakeAPI {
+	t.Helper()
+	api := &fakeAPI{}
+	api.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		api.mu.Lock()
+		api.bodies = append(api.bodies, body)
+		api.headers = append(api.headers, r.Header.Clone())
+		api.mu.Unlock()
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		respond(w, r)
+	}))
+	t.Cleanup(api.Close)
+	return api
+}
+
+// requests returns how many requests the server received.
+func (api *fakeAPI) requests() int {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	return len(api.bodies)
+}
+
+// lastTasks decodes the tasks of the most recent request.
+func (api *fakeAPI) lastTasks(t *testing.T) []map[string]any {
+	t.Helper()
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	if len(api.bodies) == 0 {
+		t.Fatal("no request was sent")
+	}
+	var tasks []map[string]any
+	if err := json.Unmarshal(api.bodies[len(api.bodies)-1], &tasks); err != nil {
+		t.Fatalf("request body is not a task array: %v", err)
+	}
+	return tasks
+}
+
+// client returns a client sending to the fake API.
+func (api *fakeAPI) client(opts ...Option) GenerateImagesV1 {
+	return NewGenerateImagesV1(testAPIKey, append([]Option{WithEndpoints([]string{api.URL})}, opts...)...)
+}
+
+const testAPIKey = "test-key-5f3a9c"
+
+// respondJSON answers with status and body as JSON.
+func respondJSON(status int, body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		io.WriteString(w, body)
+	}
+}
+
+// echoResults answers every task of the request with one result carrying its
+// taskUUID and an imageUUID derived from it.
+func echoResults(w http.ResponseWriter, r *http.Request) {
+	var tasks []map[string]any
+	json.NewDecoder(r.Body).Decode(&tasks)
+	data := make([]map[string]any, len(tasks))
+	for i, task := range tasks {
+		data[i] = map[string]any{"taskType": task["taskType"], "taskUUID": task["taskUUID"], "imageUUID": "image-" + task["taskUUID"].(string)}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"data": data})
+}
+
+// testTask returns a valid imageInference task with values overriding its
+// defaults; a nil value removes a key.
+func testTask(values map[string]any) map[string]any {
+	task := map[string]any{
+		"taskType": ImageInference,
+		"prompt":   "a dragon flying over mountains",
+		"model":    "runware:100@1",
+		"width":    512,
+		"height":   512,
+	}
+	for key, value := range values {
+		if value == nil {
+			delete(task, key)
+			continue
+		}
+		task[key] = value
+	}
+	return task
+}
+
+func TestConfigTracksOmissionsPerTask(t *testing.T) {
+	tests := []struct {
+		name  string
+		tasks []map[string]any
+		want  []any // checkNSFW per task, nil when absent
+	}{
+		{
+			name:  "omitted then false",
+			tasks: []map[string]any{testTask(nil), testTask(map[string]any{"checkNSFW": false})},
+			want:  []any{nil, false},
+		},
+		{
+			name:  "false then omitted",
+			tasks: []map[string]any{testTask(map[string]any{"checkNSFW": false}), testTask(nil)},
+			want:  []any{false, nil},
+		},
+		{
+			name:  "true then false",
+			tasks: []map[string]any{testTask(map[string]any{"checkNSFW": true}), testTask(map[string]any{"checkNSFW": false})},
+			want:  []any{true, false},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newFakeAPI(t, echoResults)
+			if _, err := api.client().Config(tt.tasks).GenerateV1(); err != nil {
+				t.Fatal(err)
+			}
+			for i, task := range api.lastTasks(t) {
+				got, present := task["checkNSFW"]
+				if want := tt.want[i]; (want == nil && present) || (want != nil && got != want) {
+					t.Errorf("task %d: checkNSFW = %v (present %v), want %v", i, got, present, want)
+				}
+			}
+		})
+	}
+}
+
+func TestConfigTwiceResetsPreviousTasks(t *testing.T) {
+	api := newFakeAPI(t, echoResults)
+	client := api.client()
+	client.Config([]map[string]any{testTask(nil), testTask(map[string]any{"checkNSFW": true, "width": "wide"})})
+	if err := client.Validate(); err == nil {
+		t.Fatal("first configuration should be invalid")
+	}
+
+	if _, err := client.Config([]map[string]any{testTask(map[string]any{"checkNSFW": false})}).GenerateV1(); err != nil {
+		t.Fatalf("second configuration kept errors from the first: %v", err)
+	}
+	tasks := api.lastTasks(t)
+	if len(tasks) != 1 {
+		t.Fatalf("sent %d tasks, want only the second configuration's one", len(tasks))
+	}
+	if got, ok := tasks[0]["checkNSFW"]; !ok || got != false {
+		t.Errorf("checkNSFW = %v (present %v), want false", got, ok)
+	}
+
+	if _, err := client.Config([]map[string]any{testTask(nil)}).GenerateV1(); err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := api.lastTasks(t)[0]["checkNSFW"]; ok {
+		t.Errorf("checkNSFW = %v leaked from the previous configuration", got)
+	}
+}
+
+func TestConfigOmitsUnsetDimensions(t *testing.T) {
+	tests := []struct {
+		name       string
+		values     map[string]any
+		wantWidth  any
+		wantHeight any
+	}{
+		{"omitted", map[string]any{"width": nil, "height": nil}, nil, nil},
+		{"explicit", map[string]any{"width": SD_Width, "height": 768}, float64(512), float64(768)},
+		{"zero by mistake", map[string]any{"width": 0, "height": Definition(0)}, nil, nil},
+		{"json float", map[string]any{"width": 1024.0, "height": nil}, float64(1024), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newFakeAPI(t, echoResults)
+			if _, err := api.client().Config([]map[string]any{testTask(tt.values)}).GenerateV1(); err != nil {
+				t.Fatal(err)
+			}
+			task := api.lastTasks(t)[0]
+			for key, want := range map[string]any{"width": tt.wantWidth, "height": tt.wantHeight} {
+				if got, ok := task[key]; want == nil && ok || want != nil && got != want {
+					t.Errorf("%s = %v (present %v), want %v", key, got, ok, want)
+				}
+			}
+		})
+	}
+}
+
+func TestAuthHeader(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       []Option
+		header     string
+		want       string
+		wantAbsent string
+	}{
+		{"default bearer", nil, "Authorization", "Bearer " + testAPIKey, ""},
+		{"api key header", []Option{WithAuthHeader("X-API-Key", "%s")}, "X-API-Key", testAPIKey, "Authorization"},
+		{"custom scheme", []Option{WithAuthHeader("Authorization", "Token %s")}, "Authorization", "Token " + testAPIKey, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newFakeAPI(t, echoResults)
+			if _, err := api.client(tt.opts...).Config([]map[string]any{testTask(nil)}).GenerateV1(); err != nil {
+				t.Fatal(err)
+			}
+			header := api.headers[0]
+			if got := header.Get(tt.header); got != tt.want {
+				t.Errorf("%s = %q, want %q", tt.header, got, tt.want)
+			}
+			if tt.wantAbsent != "" && header.Get(tt.wantAbsent) != "" {
+				t.Errorf("%s is set to %q, want it absent", tt.wantAbsent, header.Get(tt.wantAbsent))
+			}
+		})
+	}
+}
+
+func TestConfigNilTask(t *testing.T) {
+	api := newFakeAPI(t, echoResults)
+	client := api.client().Config([]map[string]any{testTask(nil), nil})
+	err := client.Validate()
+	if err == nil || !strings.Contains(err.Error(), "task 1: nil map") {
+		t.Fatalf("Validate() = %v, want an error naming task 1", err)
+	}
+	if _, err := client.GenerateV1(); err == nil || !strings.Contains(err.Error(), "task 1: nil map") {
+		t.Fatalf("GenerateV1() = %v, want an error naming task 1", err)
+	}
+	if api.requests() != 0 {
+		t.Errorf("server got %d requests, want none", api.requests())
+	}
+}
+
+func TestConfigLeavesOutNilValues(t *testing.T) {
+	api := newFakeAPI(t, echoResults)
+	task := testTask(nil)
+	nils := map[string]any{
+		"negativePrompt": nil,
+		"style":          (*string)(nil),
+		"CFGScale":       (*float64)(nil),
+		"seed":           (*int64)(nil),
+		"inputImages":    []string(nil),
+		"raw":            map[string]any(nil),
+	}
+	for key, value := range nils {
+		task[key] = value
+	}
+	if _, err := api.client().Config([]map[string]any{task}).GenerateV1(); err != nil {
+		t.Fatalf("GenerateV1() = %v", err)
+	}
+	sent := api.lastTasks(t)[0]
+	for key := range nils {
+		if value, ok := sent[key]; ok {
+			t.Errorf("%s = %v was sent, want it left out", key, value)
+		}
+	}
+}
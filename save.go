@@ -0,0 +1,250 @@
+package runware
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// DefaultFilenameTemplate names files written by GenerateAndSave unless
+// WithFilenameTemplate overrides it.
+const DefaultFilenameTemplate = "{imageUUID}.{ext}"
+
+func (g *generateImagesV1Impl) GenerateAndSave(dir string) ([]string, error) {
+	resp, err := g.GenerateV1()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+	paths := make([]string, 0, len(*resp))
+	for i, image := range *resp {
+		data, declared, err := g.resultImage(context.Background(), image)
+		if err != nil {
+			return paths, fmt.Errorf("result %d: %w", i, err)
+		}
+		ext := extensionForMIME(detectImageType(data, declared))
+		if ext == "" {
+			ext = extensionFor(g.outputFormatFor(image.TaskUUID))
+		}
+		path, err := g.savePath(dir, i, image, ext)
+		if err != nil {
+			return paths, fmt.Errorf("result %d: %w", i, err)
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			return paths, fmt.Errorf("failed to write file: %w", err)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// SaveImage decodes a result and writes it to basePath plus an extension
+// chosen from the image bytes themselves, since the server may return a
+// different format than the one requested. It returns the written path and
+// the detected MIME type.
+func SaveImage(image RunwareSuccessResponseBody, basePath string) (string, string, error) {
+	data, declared, err := decodeImage(image)
+	if err != nil {
+		return "", "", err
+	}
+	mimeType := detectImageType(data, declared)
+	path := basePath
+	if ext := extensionForMIME(mimeType); ext != "" {
+		path += "." + ext
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", "", fmt.Errorf("failed to write file: %w", err)
+	}
+	return path, mimeType, nil
+}
+
+// detectImageType sniffs the MIME type of image data, falling back to the
+// declared type when the bytes are not recognised.
+func detectImageType(data []byte, declared string) string {
+	detected := http.DetectContentType(data)
+	if strings.HasPrefix(detected, "image/") || declared == "" {
+		return detected
+	}
+	return declared
+}
+
+// filename expands the client's filename template for the i-th result.
+// Supported placeholders are {taskUUID}, {imageUUID}, {index} and {ext}. The
+// UUIDs come from the server, so one that could leave the directory, with a
+// path separator or "..", is an error.
+func (g *generateImagesV1Impl) filename(i int, image RunwareSuccessResponseBody, ext string) (string, error) {
+	for _, field := range []struct{ name, value string }{{"taskUUID", image.TaskUUID}, {"imageUUID", image.ImageUUID}} {
+		if strings.ContainsAny(field.value, `/\`) || strings.Contains(field.value, "..") {
+			return "", fmt.Errorf("%s %q cannot be used in a file name", field.name, field.value)
+		}
+	}
+	template := g.filenameTemplate
+	if template == "" {
+		template = DefaultFilenameTemplate
+	}
+	return strings.NewReplacer(
+		"{taskUUID}", image.TaskUUID,
+		"{imageUUID}", image.ImageUUID,
+		"{index}", strconv.Itoa(i),
+		"{ext}", ext,
+	).Replace(template), nil
+}
+
+// savePath returns where GenerateAndSave writes the i-th result, making sure
+// it stays inside dir whatever the template expands to.
+func (g *generateImagesV1Impl) savePath(dir string, i int, image RunwareSuccessResponseBody, ext string) (string, error) {
+	name, err := g.filename(i, image, ext)
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, name)
+	rel, err := filepath.Rel(filepath.Clean(dir), path)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("file name %q is outside the output directory", name)
+	}
+	return path, nil
+}
+
+// outputFormatFor returns the output format configured for the task that
+// produced a result.
+func (g *generateImagesV1Impl) outputFormatFor(taskUUID string) OutputFormat {
+	for _, option := range g.options {
+		if option.TaskUUID == taskUUID {
+			return g.withDefaultOutput(option).OutputFormat
+		}
+	}
+	return ""
+}
+
+// extensionFor maps an output format to a file extension. The API returns
+// JPEG when no format is requested.
+func extensionFor(format OutputFormat) string {
+	switch format {
+	case PNG:
+		return "png"
+	case WEBP:
+		return "webp"
+	default:
+		return "jpg"
+	}
+}
+
+// resultImage returns the raw image bytes of a result like decodeImage,
+// downloading results that only carry a URL with the client's HTTP client.
+func (g *generateImagesV1Impl) resultImage(ctx context.Context, image RunwareSuccessResponseBody) ([]byte, string, error) {
+	if image.ImageBase64Data == "" && image.ImageDataURI == "" && image.ImageUrl != "" {
+		return downloadImage(ctx, g.httpClient, image, g.maxResponseBytes)
+	}
+	return decodeImage(image)
+}
+
+// downloadImage fetches the image of a URL result with client, reading at
+// most limit bytes, or any amount if limit is zero or less. It returns the
+// media type the server declared along with the bytes.
+func downloadImage(ctx context.Context, client *http.Client, image RunwareSuccessResponseBody, limit int64) ([]byte, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, image.ImageUrl, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to download image %s: %w", image.ImageUUID, err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to download image %s: %w", image.ImageUUID, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("failed to download image %s: status %d", image.ImageUUID, resp.StatusCode)
+	}
+	var body io.Reader = resp.Body
+	if limit > 0 {
+		body = &maxBytesReader{r: resp.Body, limit: limit}
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to download image %s: %w", image.ImageUUID, err)
+	}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	return data, mediaType, nil
+}
+
+// decodeImage returns the raw image bytes carried by a result, along with
+// their MIME type when the result declares one (data URIs do).
+func decodeImage(image RunwareSuccessResponseBody) ([]byte, string, error) {
+	if image.ImageBase64Data != "" {
+		data, err := decodeBase64(image.ImageBase64Data)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to decode base64: %w", err)
+		}
+		return data, "", nil
+	}
+	if image.ImageDataURI != "" {
+		return decodeDataURI(image.ImageDataURI)
+	}
+	return nil, "", fmt.Errorf("image %s has no base64 data", image.ImageUUID)
+}
+
+// decodeDataURI decodes a "data:[<mediatype>][;base64],<data>" URI.
+func decodeDataURI(uri string) ([]byte, string, error) {
+	rest, ok := strings.CutPrefix(uri, "data:")
+	if !ok {
+		return nil, "", fmt.Errorf("invalid data URI: missing data: prefix")
+	}
+	header, payload, ok := strings.Cut(rest, ",")
+	if !ok {
+		return nil, "", fmt.Errorf("invalid data URI: missing comma")
+	}
+	mediaType, isBase64 := strings.CutSuffix(header, ";base64")
+	mediaType, _, _ = strings.Cut(mediaType, ";")
+	if !isBase64 {
+		data, err := url.PathUnescape(payload)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid data URI: %w", err)
+		}
+		return []byte(data), mediaType, nil
+	}
+	data, err := decodeBase64(payload)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to decode base64: %w", err)
+	}
+	return data, mediaType, nil
+}
+
+// decodeBase64 decodes standard or URL-safe base64, with or without padding,
+// ignoring line breaks.
+func decodeBase64(s string) ([]byte, error) {
+	s = strings.Map(func(r rune) rune {
+		if r == '\r' || r == '\n' {
+			return -1
+		}
+		return r
+	}, s)
+	s = strings.TrimRight(s, "=")
+	if strings.ContainsAny(s, "-_") {
+		return base64.RawURLEncoding.DecodeString(s)
+	}
+	return base64.RawStdEncoding.DecodeString(s)
+}
+
+// extensionForMIME maps an image MIME type to a file extension, returning ""
+// for types it does not know.
+func extensionForMIME(mimeType string) string {
+	switch strings.ToLower(mimeType) {
+	case "image/png":
+		return "png"
+	case "image/jpeg", "image/jpg":
+		return "jpg"
+	case "image/webp":
+		return "webp"
+	default:
+		return ""
+	}
+}
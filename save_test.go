@@ -0,0 +1,179 @@
+package runware
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testPNG(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 8, 8))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestGenerateAndSaveEveryOutputType(t *testing.T) {
+	data := testPNG(t)
+	encoded := base64.StdEncoding.EncodeToString(data)
+	tests := []struct {
+		name       string
+		outputType OutputType
+		result     map[string]any
+		wantErr    bool
+	}{
+		{"base64", Base64Data, map[string]any{"imageBase64Data": encoded}, false},
+		{"data URI", DataURI, map[string]any{"imageDataURI": "data:image/png;base64," + encoded}, false},
+		{"URL", URL, map[string]any{"imageUrl": "/images/ok.png"}, false},
+		{"missing URL", URL, map[string]any{"imageUrl": "/images/missing.png"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var api *fakeAPI
+			api = newFakeAPI(t, func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/images/ok.png":
+					w.Header().Set("Content-Type", "image/png")
+					w.Write(data)
+					return
+				case "/images/missing.png":
+					http.NotFound(w, r)
+					return
+				}
+				result := map[string]any{"taskType": "imageInference", "taskUUID": "task", "imageUUID": "saved"}
+				for key, value := range tt.result {
+					if key == "imageUrl" {
+						value = api.URL + value.(string)
+					}
+					result[key] = value
+				}
+				w.Header().Set("Content-Type", "application/json")
+				json.NewEncoder(w).Encode(map[string]any{"data": []any{result}})
+			})
+			dir := t.TempDir()
+			paths, err := api.client().Config([]map[string]any{testTask(map[string]any{"outputType": tt.outputType, "outputFormat": PNG})}).GenerateAndSave(dir)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("GenerateAndSave succeeded for a missing image")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := []string{filepath.Join(dir, "saved.png")}; len(paths) != 1 || paths[0] != want[0] {
+				t.Fatalf("paths = %v, want %v", paths, want)
+			}
+			written, err := os.ReadFile(paths[0])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(written, data) {
+				t.Error("written file differs from the generated image")
+			}
+		})
+	}
+}
+
+func testJPEG(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, image.NewGray(image.Rect(0, 0, 8, 8)), nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestSaveImageDetectsFormat(t *testing.T) {
+	jpegData := testJPEG(t)
+	pngData := testPNG(t)
+	tests := []struct {
+		name     string
+		image    RunwareSuccessResponseBody
+		wantExt  string
+		wantMIME string
+	}{
+		{"jpeg declared as png", RunwareSuccessResponseBody{ImageDataURI: "data:image/png;base64," + base64.StdEncoding.EncodeToString(jpegData)}, ".jpg", "image/jpeg"},
+		{"png in base64", RunwareSuccessResponseBody{ImageBase64Data: base64.StdEncoding.EncodeToString(pngData)}, ".png", "image/png"},
+		{"unknown bytes declared as webp", RunwareSuccessResponseBody{ImageDataURI: "data:image/webp;base64," + base64.StdEncoding.EncodeToString([]byte("not an image"))}, ".webp", "image/webp"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base := filepath.Join(t.TempDir(), "out")
+			path, mimeType, err := SaveImage(tt.image, base)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if path != base+tt.wantExt || mimeType != tt.wantMIME {
+				t.Errorf("SaveImage = %s, %s; want %s, %s", path, mimeType, base+tt.wantExt, tt.wantMIME)
+			}
+		})
+	}
+}
+
+func TestGenerateAndSaveUsesDetectedExtension(t *testing.T) {
+	encoded := base64.StdEncoding.EncodeToString(testJPEG(t))
+	api := newFakeAPI(t, respondJSON(http.StatusOK, `{"data":[{"taskType":"imageInference","taskUUID":"task","imageUUID":"photo","imageBase64Data":"`+encoded+`"}]}`))
+	dir := t.TempDir()
+	paths, err := api.client().Config([]map[string]any{testTask(map[string]any{"outputType": Base64Data, "outputFormat": PNG})}).GenerateAndSave(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(dir, "photo.jpg"); len(paths) != 1 || paths[0] != want {
+		t.Errorf("paths = %v, want [%s] for JPEG bytes requested as PNG", paths, want)
+	}
+}
+
+func TestGenerateAndSaveStaysInDir(t *testing.T) {
+	encoded := base64.StdEncoding.EncodeToString(testPNG(t))
+	tests := []struct {
+		name      string
+		taskUUID  string
+		imageUUID string
+		template  string
+		wantErr   string
+	}{
+		{"parent imageUUID", "task", "../../x", "", `imageUUID "../../x" cannot be used in a file name`},
+		{"absolute imageUUID", "task", "/etc/x", "", `imageUUID "/etc/x" cannot be used in a file name`},
+		{"backslash taskUUID", `..\x`, "img", "{taskUUID}.{ext}", `taskUUID "..\\x" cannot be used in a file name`},
+		{"template leaving dir", "task", "img", "../{imageUUID}.{ext}", "is outside the output directory"},
+		{"safe template", "task", "img", "{taskUUID}-{imageUUID}.{ext}", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, _ := json.Marshal(map[string]any{"data": []any{map[string]any{
+				"taskType": "imageInference", "taskUUID": tt.taskUUID, "imageUUID": tt.imageUUID, "imageBase64Data": encoded,
+			}}})
+			api := newFakeAPI(t, respondJSON(http.StatusOK, string(result)))
+			parent := t.TempDir()
+			dir := filepath.Join(parent, "out")
+			var opts []Option
+			if tt.template != "" {
+				opts = append(opts, WithFilenameTemplate(tt.template))
+			}
+			paths, err := api.client(opts...).Config([]map[string]any{testTask(map[string]any{"outputType": Base64Data, "outputFormat": PNG})}).GenerateAndSave(dir)
+			if tt.wantErr == "" {
+				if err != nil || len(paths) != 1 || filepath.Dir(paths[0]) != dir {
+					t.Fatalf("GenerateAndSave = %v, %v, want one file in %s", paths, err, dir)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("GenerateAndSave = %v, want an error containing %q", err, tt.wantErr)
+			}
+			if entries, _ := os.ReadDir(parent); len(entries) != 1 {
+				t.Errorf("files were written next to the output directory: %v", entries)
+			}
+		})
+	}
+}
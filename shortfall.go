@@ -0,0 +1,39 @@
+package runware
+
+// ResultShortfall describes a task that returned fewer results than it asked
+// for, e.g. because some images were filtered as NSFW or failed.
+type ResultShortfall struct {
+	TaskUUID string
+	// Expected is the task's numberOfResults, or one when it was not set.
+	Expected int
+	Received int
+}
+
+// resultShortfalls compares the results received for each task in options
+// with the number it asked for.
+func resultShortfalls(options []RunwareOptions, results []RunwareSuccessResponseBody) []ResultShortfall {
+	received := make(map[string]int, len(options))
+	for _, r := range results {
+		received[r.TaskUUID]++
+	}
+	var shortfalls []ResultShortfall
+	for _, option := range options {
+		expected := max(int(option.NumberOfResults), 1)
+		if received[option.TaskUUID] < expected {
+			shortfalls = append(shortfalls, ResultShortfall{
+				TaskUUID: option.TaskUUID,
+				Expected: expected,
+				Received: received[option.TaskUUID],
+			})
+		}
+	}
+	return shortfalls
+}
+
+// LastShortfalls returns the tasks of the most recent call that received fewer
+// results than they asked for, or nil if every task got all of its results.
+func (g *generateImagesV1Impl) LastShortfalls() []ResultShortfall {
+	g.state.mu.Lock()
+	defer g.state.mu.Unlock()
+	return g.state.lastShortfalls
+}
@@ -0,0 +1,285 @@
+package runware
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+)
+
+// ImageSink returns the destination for the decoded bytes of the image at
+// position index of a response's data array. If the returned writer is also
+// an io.Closer it is closed once the image has been written.
+type ImageSink func(index int) (io.Writer, error)
+
+// GenerateToSink runs the configured tasks like GenerateV1Context, but streams
+// every imageBase64Data payload from the response body through a base64
+// decoder into the writer returned by sink instead of holding it in memory.
+// The returned results carry everything else the API sent, in the same order
+// as the sink indexes, with ImageBase64Data left empty.
+//
+// Only base64Data output is streamed; URL and data URI results are returned as
+// usual. Hedging is disabled for these calls, but retries are not: when an
+// attempt is retried the sink is asked again for the same indexes, so it
+// should truncate rather than append (os.Create does).
+func (g *generateImagesV1Impl) GenerateToSink(ctx context.Context, sink ImageSink) (*[]RunwareSuccessResponseBody, error) {
+	if sink == nil {
+		return nil, fmt.Errorf("image sink must not be nil")
+	}
+	streamed := *g
+	streamed.imageSink = sink
+	streamed.hedgeDelay = 0
+	return streamed.GenerateV1Context(ctx)
+}
+
+const (
+	imageDataKey = "imageBase64Data"
+	dataKey      = "data"
+)
+
+// imageStreamReader passes a JSON response through unchanged, except that the
+// contents of the imageBase64Data string of every result in the top-level
+// data array are diverted to the sink and dropped, so the decoder sees an
+// empty string. It tracks just enough JSON syntax to tell object keys from
+// string values and to know which result of the data array it is in.
+type imageStreamReader struct {
+	r    io.Reader
+	sink ImageSink
+
+	state   int
+	key     []byte
+	keyLong bool // the string is too long, or escaped, to be a key we match
+	lastKey string
+	depth   int  // number of objects and arrays open
+	toData  bool // the value being started is that of the top-level data key
+	inData  bool // inside the top-level data array
+	element int  // position in the data array of the current result
+	current *base64Writer
+	err     error
+}
+
+const (
+	scanOutside = iota
+	scanString
+	scanStringEscape
+	scanValue
+	scanImage
+	scanImageEscape
+)
+
+func newImageStreamReader(r io.Reader, sink ImageSink) *imageStreamReader {
+	return &imageStreamReader{r: r, sink: sink, key: make([]byte, 0, len(imageDataKey)), element: -1}
+}
+
+func (s *imageStreamReader) Read(p []byte) (int, error) {
+	for {
+		if s.err != nil {
+			return 0, s.err
+		}
+		n, err := s.r.Read(p)
+		out, filterErr := s.filter(p[:n])
+		if filterErr != nil {
+			s.err = filterErr
+			return 0, filterErr
+		}
+		if out > 0 || err != nil {
+			if err == io.EOF && s.current != nil {
+				err = io.ErrUnexpectedEOF
+			}
+			return out, err
+		}
+	}
+}
+
+// filter compacts p in place, keeping the bytes meant for the JSON decoder,
+// and returns how many were kept.
+func (s *imageStreamReader) filter(p []byte) (int, error) {
+	out := 0
+	start := -1 // start of the pending run of image bytes in p
+	flush := func(end int) error {
+		if start < 0 {
+			return nil
+		}
+		_, err := s.current.Write(p[start:end])
+		start = -1
+		return err
+	}
+	for i := 0; i < len(p); i++ {
+		c := p[i]
+		switch s.state {
+		case scanImage:
+			switch c {
+			case '\\':
+				if err := flush(i); err != nil {
+					return 0, err
+				}
+				s.state = scanImageEscape
+			case '"':
+				if err := flush(i); err != nil {
+					return 0, err
+				}
+				if err := s.current.Close(); err != nil {
+					return 0, err
+				}
+				s.current = nil
+				s.state = scanOutside
+				p[out] = c
+				out++
+			default:
+				if start < 0 {
+					start = i
+				}
+			}
+			continue
+		case scanImageEscape:
+			// Base64 only needs escaping for "\/"; the escaped byte is data.
+			if _, err := s.current.Write(p[i : i+1]); err != nil {
+				return 0, err
+			}
+			s.state = scanImage
+			continue
+		case scanString:
+			switch c {
+			case '\\':
+				s.state = scanStringEscape
+				s.keyLong = true // escaped keys never match
+			case '"':
+				s.lastKey = ""
+				if !s.keyLong {
+					switch string(s.key) {
+					case imageDataKey:
+						s.lastKey = imageDataKey
+					case dataKey:
+						s.lastKey = dataKey
+					}
+				}
+				s.state = scanOutside
+			default:
+				if len(s.key) < len(imageDataKey) {
+					s.key = append(s.key, c)
+				} else {
+					s.keyLong = true
+				}
+			}
+		case scanStringEscape:
+			s.state = scanString
+		case scanValue:
+			switch c {
+			case ' ', '\t', '\n', '\r':
+			case '"':
+				w, err := s.open()
+				if err != nil {
+					return 0, err
+				}
+				s.current = w
+				s.state = scanImage
+			default:
+				// Not a string: read it like any other value.
+				s.state = scanOutside
+				i--
+				continue
+			}
+		case scanOutside:
+			switch c {
+			case ' ', '\t', '\n', '\r':
+			case ':':
+				// Results are the objects directly inside the data array.
+				if s.inData && s.depth == 3 && s.lastKey == imageDataKey {
+					s.state = scanValue
+				}
+				s.toData = s.depth == 1 && s.lastKey == dataKey
+				s.lastKey = ""
+			default:
+				s.scanOutside(c)
+			}
+		}
+		p[out] = c
+		out++
+	}
+	if err := flush(len(p)); err != nil {
+		return 0, err
+	}
+	return out, nil
+}
+
+// scanOutside follows the structure of the response outside strings.
+func (s *imageStreamReader) scanOutside(c byte) {
+	s.lastKey = ""
+	toData := s.toData
+	s.toData = false
+	switch c {
+	case '"':
+		s.key = s.key[:0]
+		s.keyLong = false
+		s.state = scanString
+	case '[':
+		s.inData = s.inData || toData
+		s.depth++
+	case '{':
+		if s.inData && s.depth == 2 {
+			s.element++
+		}
+		s.depth++
+	case '}', ']':
+		s.depth--
+		if s.depth < 2 {
+			s.inData = false
+		}
+	}
+}
+
+// open asks the sink for the writer of the image of the current result.
+func (s *imageStreamReader) open() (*base64Writer, error) {
+	w, err := s.sink(s.element)
+	if err != nil {
+		return nil, fmt.Errorf("image sink for result %d: %w", s.element, err)
+	}
+	return &base64Writer{w: w}, nil
+}
+
+// base64Writer decodes standard base64 written to it into w, a whole quantum
+// at a time.
+type base64Writer struct {
+	w       io.Writer
+	pending []byte
+	buf     []byte
+}
+
+func (b *base64Writer) Write(p []byte) (int, error) {
+	b.pending = append(b.pending, p...)
+	whole := len(b.pending) / 4 * 4
+	if whole == 0 {
+		return len(p), nil
+	}
+	if err := b.decode(b.pending[:whole]); err != nil {
+		return 0, err
+	}
+	b.pending = append(b.pending[:0], b.pending[whole:]...)
+	return len(p), nil
+}
+
+// Close decodes what is left and closes w if it can be closed.
+func (b *base64Writer) Close() error {
+	err := b.decode(b.pending)
+	if c, ok := b.w.(io.Closer); ok {
+		if closeErr := c.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+func (b *base64Writer) decode(src []byte) error {
+	if len(src) == 0 {
+		return nil
+	}
+	if need := base64.StdEncoding.DecodedLen(len(src)); cap(b.buf) < need {
+		b.buf = make([]byte, need)
+	}
+	n, err := base64.StdEncoding.Decode(b.buf[:cap(b.buf)], src)
+	if err != nil {
+		return fmt.Errorf("failed to decode base64 image data: %w", err)
+	}
+	_, err = b.w.Write(b.buf[:n])
+	return err
+}
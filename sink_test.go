@@ -0,0 +1,66 @@
+package runware
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestImageStreamReaderIndexesByPosition(t *testing.T) {
+	first := base64.StdEncoding.EncodeToString([]byte("first image"))
+	third := base64.StdEncoding.EncodeToString([]byte("third image"))
+	nested := base64.StdEncoding.EncodeToString([]byte("not an image"))
+	response := `{"data": [
+		{"taskUUID": "a", "imageBase64Data": "` + first + `"},
+		{"taskUUID": "b", "imageURL": "https://example.com/b.png", "meta": {"imageBase64Data": "` + nested + `"}},
+		{"taskUUID": "c", "imageBase64Data" : null, "tags": ["imageBase64Data", {"data": [{"imageBase64Data": "` + nested + `"}]}]},
+		{"taskUUID": "d", "imageBase64Data": "` + strings.ReplaceAll(third, "/", `\/`) + `"}
+	], "errors": [{"imageBase64Data": "` + nested + `"}]}`
+
+	for name, wrap := range map[string]func(io.Reader) io.Reader{
+		"whole":    func(r io.Reader) io.Reader { return r },
+		"byteWise": iotest.OneByteReader,
+	} {
+		t.Run(name, func(t *testing.T) {
+			images := map[int]*bytes.Buffer{}
+			sink := func(index int) (io.Writer, error) {
+				images[index] = new(bytes.Buffer)
+				return images[index], nil
+			}
+			out, err := io.ReadAll(newImageStreamReader(wrap(strings.NewReader(response)), sink))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := map[int]string{}
+			for index, buf := range images {
+				got[index] = buf.String()
+			}
+			want := map[int]string{0: "first image", 3: "third image"}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("sink got %q, want %q", got, want)
+			}
+
+			var decoded struct {
+				Data   []map[string]any `json:"data"`
+				Errors []map[string]any `json:"errors"`
+			}
+			if err := json.Unmarshal(out, &decoded); err != nil {
+				t.Fatalf("filtered response is not JSON: %v\n%s", err, out)
+			}
+			if decoded.Data[0]["imageBase64Data"] != "" || decoded.Data[3]["imageBase64Data"] != "" {
+				t.Errorf("streamed images left in the response: %s", out)
+			}
+			if decoded.Data[1]["meta"].(map[string]any)["imageBase64Data"] != nested {
+				t.Errorf("nested imageBase64Data was diverted: %s", out)
+			}
+			if decoded.Errors[0]["imageBase64Data"] != nested {
+				t.Errorf("imageBase64Data outside the data array was diverted: %s", out)
+			}
+		})
+	}
+}
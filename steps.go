@@ -0,0 +1,48 @@
+package runware
+
+import (
+	"fmt"
+	"log"
+)
+
+// MaxSteps is the most inference steps the API accepts for any model.
+const MaxSteps = 100
+
+// modelMaxSteps lists models, by AIR identifier, that are distilled to run in
+// far fewer steps than MaxSteps; more steps only cost more.
+var modelMaxSteps = map[string]int{
+	"runware:100@1": 4, // FLUX.1 [schnell]
+}
+
+// maxStepsFor returns the most steps worth sending for a task, going by its
+// architecture when set and its model otherwise.
+func maxStepsFor(option RunwareOptions) int {
+	if profile, ok := architectureProfiles[option.Architecture]; ok {
+		return profile.maxSteps
+	}
+	if limit, ok := modelMaxSteps[option.Model]; ok {
+		return limit
+	}
+	return MaxSteps
+}
+
+// checkSteps reports a step count above the model's maximum, unless
+// WithStepClamping is enabled. Counts above MaxSteps are left to checkRanges.
+func (g *generateImagesV1Impl) checkSteps(i int, option RunwareOptions) error {
+	limit := maxStepsFor(option)
+	if option.Steps <= limit || option.Steps > MaxSteps || g.clampSteps {
+		return nil
+	}
+	return fmt.Errorf("task %d: steps %d exceeds the maximum of %d for model %q", i, option.Steps, limit, option.Model)
+}
+
+// steps returns the step count to send, clamped to the model's maximum when
+// WithStepClamping is enabled.
+func (g *generateImagesV1Impl) steps(i int, option RunwareOptions) int {
+	limit := maxStepsFor(option)
+	if !g.clampSteps || option.Steps <= limit {
+		return option.Steps
+	}
+	log.Printf("task %d: clamping steps from %d to %d for model %q", i, option.Steps, limit, option.Model)
+	return limit
+}
@@ -0,0 +1,15 @@
+package runware
+
+// Strength is how much image-to-image may change the seed image, from 0 (keep
+// it as is) to 1 (ignore it). The named levels are sensible starting points;
+// any float64 between 0 and 1 can still be passed as "strength" to Config.
+type Strength float64
+
+const (
+	StrengthSubtle   Strength = 0.2
+	StrengthBalanced Strength = 0.5
+	StrengthStrong   Strength = 0.8
+)
+
+// Float returns the value sent to the API.
+func (s Strength) Float() float64 { return float64(s) }
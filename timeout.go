@@ -0,0 +1,54 @@
+package runware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// TaskTimeoutError is returned when the deadline of a GenerateV1Context call
+// expired before every task produced a result. The REST API answers a batch
+// as a whole, so a task counts as timed out when no result for its taskUUID
+// had arrived, in any attempt, by the cutoff. Results that did arrive are
+// returned alongside this error.
+type TaskTimeoutError struct {
+	TaskUUIDs []string
+	Err       error
+}
+
+func (e *TaskTimeoutError) Error() string {
+	return fmt.Sprintf("%d tasks timed out %v: %v", len(e.TaskUUIDs), e.TaskUUIDs, e.Err)
+}
+
+func (e *TaskTimeoutError) Unwrap() error { return e.Err }
+
+func (e *TaskTimeoutError) Is(target error) bool { return target == ErrTimeout }
+
+// cutOff finishes a failed call. When the failure is due to the deadline it
+// returns the results collected so far together with the tasks still
+// pending; otherwise it returns err alone.
+func cutOff(ctx context.Context, g *generateImagesV1Impl, results []RunwareSuccessResponseBody, err error) (*[]RunwareSuccessResponseBody, error) {
+	if !errors.Is(err, context.DeadlineExceeded) && !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return nil, err
+	}
+	pending := pendingTasks(g.options, results)
+	if len(pending) == 0 {
+		return &results, nil
+	}
+	return &results, &TaskTimeoutError{TaskUUIDs: pending, Err: err}
+}
+
+// pendingTasks returns the taskUUIDs of options without any result.
+func pendingTasks(options []RunwareOptions, results []RunwareSuccessResponseBody) []string {
+	done := make(map[string]bool, len(results))
+	for _, r := range results {
+		done[r.TaskUUID] = true
+	}
+	var pending []string
+	for _, option := range options {
+		if !done[option.TaskUUID] {
+			pending = append(pending, option.TaskUUID)
+		}
+	}
+	return pending
+}
@@ -0,0 +1,29 @@
+package runware
+
+import (
+	"net/http"
+	"time"
+)
+
+// Connection pool defaults, sized for a handful of concurrent requests.
+const (
+	DefaultMaxIdleConns        = 100
+	DefaultMaxIdleConnsPerHost = 10
+	DefaultIdleConnTimeout     = 90 * time.Second
+)
+
+// HTTPClient returns the HTTP client the API is called with, including any
+// transport set by WithTransport, to download URL results the same way.
+func (g *generateImagesV1Impl) HTTPClient() *http.Client {
+	return g.httpClient
+}
+
+// newTransport returns a copy of http.DefaultTransport with the given
+// connection pool settings.
+func newTransport(maxIdleConns, maxIdleConnsPerHost int, idleTimeout time.Duration) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = maxIdleConns
+	transport.MaxIdleConnsPerHost = maxIdleConnsPerHost
+	transport.IdleConnTimeout = idleTimeout
+	return transport
+}
@@ -0,0 +1,63 @@
+package runware
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNoTasks is returned when GenerateV1 is called before any task has been
+// configured, or after Config was given an empty slice.
+var ErrNoTasks = errors.New("no tasks configured")
+
+// Validate checks the configured tasks without contacting the API and
+// returns every problem found, each prefixed with the task index.
+func (g *generateImagesV1Impl) Validate() error {
+	return g.validate(true)
+}
+
+// validate runs the checks behind Validate. GenerateV1 runs them too before
+// sending anything, except for the check of enum values, which unless
+// WithStrictConfig is set are passed through to the API as is.
+func (g *generateImagesV1Impl) validate(checkEnums bool) error {
+	errs := []error{g.optionErr, g.configErr, g.earlyConfigErr}
+	if len(g.options) == 0 {
+		errs = append(errs, ErrNoTasks)
+	}
+	for i, option := range g.options {
+		option = g.withDefaultOutput(option)
+		if option.TaskType == "" {
+			errs = append(errs, fmt.Errorf("task %d: taskType is required", i))
+		} else if !option.TaskType.IsValid() && !g.allowUnknownTasks {
+			errs = append(errs, fmt.Errorf("task %d: unsupported taskType %q, expected one of %v (see WithAllowUnknownTaskTypes)", i, option.TaskType, knownTaskTypes))
+		}
+		if checkEnums && option.OutputType != "" && !option.OutputType.IsValid() {
+			errs = append(errs, fmt.Errorf("task %d: unknown outputType %q, expected one of %v", i, option.OutputType, knownOutputTypes))
+		}
+		if checkEnums && option.OutputFormat != "" && !option.OutputFormat.IsValid() {
+			errs = append(errs, fmt.Errorf("task %d: unknown outputFormat %q, expected one of %v", i, option.OutputFormat, knownOutputFormats))
+		}
+		errs = append(errs, g.preflight(i, option)...)
+	}
+	return errors.Join(errs...)
+}
+
+// preflight runs the task checks that apply whatever the configuration.
+func (g *generateImagesV1Impl) preflight(i int, option RunwareOptions) []error {
+	errs := g.checkDimensions(i, option)
+	errs = append(errs, checkPromptPresent(i, option))
+	errs = append(errs, checkTaskRules(i, option)...)
+	errs = append(errs, checkMaskMargin(i, option)...)
+	errs = append(errs, checkOutput(i, g.withDefaultOutput(option))...)
+	errs = append(errs, g.checkPrompt(i, "prompt", option.Prompt))
+	errs = append(errs, g.checkPrompt(i, "negativePrompt", option.NegativePrompt))
+	errs = append(errs, g.checkPromptSyntax(i, option)...)
+	errs = append(errs, g.checkSteps(i, option))
+	errs = append(errs, g.checkRanges(i, option)...)
+	errs = append(errs, g.checkInputImages(i, option)...)
+	if option.Model != "" {
+		if _, err := ParseAIR(option.Model); err != nil {
+			errs = append(errs, fmt.Errorf("task %d: %w", i, err))
+		}
+	}
+	return errs
+}
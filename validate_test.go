@@ -0,0 +1,80 @@
+package runware
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestGenerateWithoutTasks(t *testing.T) {
+	tests := []struct {
+		name      string
+		configure func(GenerateImagesV1) GenerateImagesV1
+	}{
+		{"never configured", func(c GenerateImagesV1) GenerateImagesV1 { return c }},
+		{"empty Config", func(c GenerateImagesV1) GenerateImagesV1 { return c.Config([]map[string]any{}) }},
+		{"nil Config", func(c GenerateImagesV1) GenerateImagesV1 { return c.Config(nil) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newFakeAPI(t, echoResults)
+			client := tt.configure(api.client())
+			if err := client.Validate(); !errors.Is(err, ErrNoTasks) {
+				t.Errorf("Validate() = %v, want ErrNoTasks", err)
+			}
+			if _, err := client.GenerateV1(); !errors.Is(err, ErrNoTasks) {
+				t.Errorf("GenerateV1() = %v, want ErrNoTasks", err)
+			}
+			if api.requests() != 0 {
+				t.Errorf("server got %d requests, want none", api.requests())
+			}
+		})
+	}
+}
+
+func TestValidateMaskMargin(t *testing.T) {
+	inpaint := map[string]any{"seedImage": "c2VlZA==", "maskImage": "bWFzaw=="}
+	with := func(values map[string]any, margin any) map[string]any {
+		task := testTask(values)
+		task["maskMargin"] = margin
+		return task
+	}
+	tests := []struct {
+		name    string
+		task    map[string]any
+		wantErr string
+	}{
+		{"positive with mask", with(inpaint, 32), ""},
+		{"zero with mask", with(inpaint, 0), "task 0: maskMargin must be positive, got 0"},
+		{"negative with mask", with(inpaint, -8), "task 0: maskMargin must be positive, got -8"},
+		{"without mask", with(nil, 32), "task 0: maskMargin requires maskImage"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newFakeAPI(t, echoResults)
+			client := api.client().Config([]map[string]any{tt.task})
+			err := client.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() = %v", err)
+				}
+				if _, err := client.GenerateV1(); err != nil {
+					t.Fatalf("GenerateV1() = %v", err)
+				}
+				if got := api.lastTasks(t)[0]["maskMargin"]; got != float64(32) {
+					t.Errorf("maskMargin sent as %v, want 32", got)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Validate() = %v, want an error containing %q", err, tt.wantErr)
+			}
+			if _, err := client.GenerateV1(); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("GenerateV1() = %v, want an error containing %q", err, tt.wantErr)
+			}
+			if api.requests() != 0 {
+				t.Errorf("server got %d requests, want none", api.requests())
+			}
+		})
+	}
+}
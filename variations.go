@@ -0,0 +1,37 @@
+package runware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// DefaultVariationStrength is the image-to-image strength used by Variations:
+// enough to change the details while keeping the composition.
+const DefaultVariationStrength = StrengthBalanced
+
+func (g *generateImagesV1Impl) Variations(baseUUID string, n int) (*[]RunwareSuccessResponseBody, error) {
+	if len(g.options) == 0 {
+		return nil, errors.New("variations need a configured task to take the model and prompt from")
+	}
+	if n < 1 || n > 255 {
+		return nil, fmt.Errorf("invalid number of variations %d, must be between 1 and 255", n)
+	}
+	task := g.options[0]
+	task.TaskUUID = uuid.New().String()
+	task.SeedImage = baseUUID
+	task.MaskImage = ""
+	task.MaskMargin = nil
+	task.NumberOfResults = uint8(n)
+	task.Seed = nil
+	strength := DefaultVariationStrength.Float()
+	task.Strength = &strength
+	// No seed is sent, so the API picks a random one for every result. The
+	// copy shares transport, retry and breaker settings with g but leaves its
+	// configured tasks untouched.
+	variation := *g
+	variation.setOptions([]RunwareOptions{task})
+	return variation.GenerateV1Context(context.Background())
+}
@@ -0,0 +1,16 @@
+package runware
+
+// Version is the version of this SDK, sent in the User-Agent header of every
+// request. It is bumped with each release. It is an exported constant so that
+// it can be used in other constants, such as a caller's own User-Agent.
+const Version = "0.1.0"
+
+// SDKVersion returns Version, for callers that need it as a function, e.g. to
+// register it with a telemetry or build-info hook. It cannot be called Version
+// because a package cannot declare a constant and a function with one name.
+func SDKVersion() string {
+	return Version
+}
+
+// userAgent identifies the SDK to the API, e.g. for support tickets.
+const userAgent = "runware-go/" + Version
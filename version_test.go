@@ -0,0 +1,31 @@
+package runware
+
+import (
+	"net/http"
+	"regexp"
+	"testing"
+)
+
+// semver is the regular expression suggested by semver.org for version 2.0.0.
+var semver = regexp.MustCompile(`^(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)` +
+	`(?:-((?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*)(?:\.(?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*))*))?` +
+	`(?:\+([0-9a-zA-Z-]+(?:\.[0-9a-zA-Z-]+)*))?$`)
+
+func TestVersionIsSemver(t *testing.T) {
+	if !semver.MatchString(Version) {
+		t.Errorf("Version %q is not a semantic version", Version)
+	}
+	if SDKVersion() != Version {
+		t.Errorf("SDKVersion() = %q, want %q", SDKVersion(), Version)
+	}
+}
+
+func TestUserAgentCarriesVersion(t *testing.T) {
+	api := newFakeAPI(t, respondJSON(http.StatusOK, pongBody))
+	if err := api.client().Ping(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := api.headers[0].Get("User-Agent"), "runware-go/"+Version; got != want {
+		t.Errorf("User-Agent = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,43 @@
+package runware
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+)
+
+// Warmup opens a connection to every endpoint, completing the TLS handshake
+// and HTTP/2 negotiation, so that the first generation does not pay for them.
+// The connections stay in the client's idle pool for later calls. It sends a
+// HEAD request without the API key, so no credits or quota are used; any
+// response, whatever its status, counts as success. Only failing to reach an
+// endpoint is reported, as a *TransportError.
+func (g *generateImagesV1Impl) Warmup(ctx context.Context) error {
+	if g.optionErr != nil {
+		return g.optionErr
+	}
+	ctx, release, err := g.bindClose(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+	var errs []error
+	for _, u := range g.endpointURLs() {
+		req, err := http.NewRequestWithContext(ctx, http.MethodHead, u, nil)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		req.Header.Set("User-Agent", userAgent)
+		resp, err := g.httpClient.Do(req)
+		if err != nil {
+			errs = append(errs, closedErr(ctx, &TransportError{URL: u, Err: err}))
+			continue
+		}
+		// Draining the body lets the connection be reused.
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+	return errors.Join(errs...)
+}
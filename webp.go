@@ -0,0 +1,10 @@
+//go:build !runware_nowebp
+
+package runware
+
+// The WEBP decoder is registered unless building with -tags runware_nowebp,
+// for programs that never see WEBP images and would rather not link
+// golang.org/x/image/webp.
+import _ "golang.org/x/image/webp"
+
+const webpSupported = true
@@ -0,0 +1,40 @@
+//go:build !runware_nowebp
+
+package runware
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"image"
+	"strings"
+	"testing"
+)
+
+func TestDecodeImageWEBP(t *testing.T) {
+	result := RunwareSuccessResponseBody{ImageUUID: "w", ImageBase64Data: base64.StdEncoding.EncodeToString(fixture(t, "fixture.webp"))}
+	img, format, err := result.DecodeImage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if format != "webp" || img.Bounds().Size() != image.Pt(1, 1) {
+		t.Errorf("DecodeImage = %q %v, want webp (1,1)", format, img.Bounds().Size())
+	}
+	png, err := result.AsPNG(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(png, []byte("\x89PNG")) {
+		t.Error("AsPNG did not convert the WEBP image to PNG")
+	}
+}
+
+func TestResizeInputImageWEBP(t *testing.T) {
+	resized, adjustment, err := resizeInputImage("data:image/webp;base64," + base64.StdEncoding.EncodeToString(fixture(t, "fixture.webp")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if adjustment == nil || !strings.HasPrefix(resized, "data:image/png;base64,") {
+		t.Errorf("resizeInputImage = %.40q, %+v, want a resized PNG", resized, adjustment)
+	}
+}